@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/sectore/fit-activities-tui/internal/export"
+	"github.com/sectore/fit-activities-tui/internal/fit"
+	"github.com/spf13/cobra"
+)
+
+var anonymizeCmd = &cobra.Command{
+	Use:   "anonymize <file>",
+	Short: "Remove personal data (serial numbers, user profile, positions of privacy zones) of a FIT file",
+	Long: `Remove personal data of a FIT file before sharing it: serial numbers of all devices,
+user profiles (e.g. name, weight, age) and all positions inside of privacy zones (--privacy-zone).
+The original file is never changed.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output, _ := cmd.Flags().GetString("output")
+		zones, err := getPrivacyZones(cmd)
+		if err != nil {
+			return err
+		}
+
+		path := args[0]
+		act, err := fit.ReadActivity(path)
+		if err != nil {
+			return err
+		}
+		fit.Anonymize(act)
+		fit.ApplyPrivacyZones(act, zones)
+
+		if output == "" {
+			// new file next to the original file, never overwrites existing files
+			file, err := export.ActivityToFile(filepath.Dir(path), export.Name(path)+"-anonymized", act)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), file)
+			return nil
+		}
+
+		return writeOutput(cmd, output, func(w io.Writer) error {
+			if err := fit.WriteActivity(w, act); err != nil {
+				return fmt.Errorf("failed to write anonymized activity: %v", err)
+			}
+			return nil
+		})
+	},
+}
+
+func init() {
+	anonymizeCmd.Flags().StringP("output", "o", "", "File to write the anonymized activity to ('-' for stdout). Defaults to a new file '<file>-anonymized.fit'")
+	addPrivacyFlags(anonymizeCmd)
+	rootCmd.AddCommand(anonymizeCmd)
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/sectore/fit-activities-tui/internal/asyncdata"
+	"github.com/sectore/fit-activities-tui/internal/common"
+	"github.com/sectore/fit-activities-tui/internal/export"
+	"github.com/sectore/fit-activities-tui/internal/fit"
+	"github.com/spf13/cobra"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export <file> | --summary <files...>",
+	Short: "Export an activity to another format (e.g. GPX, CSV) or summaries of activities to CSV",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, _ := cmd.Flags().GetString("format")
+		output, _ := cmd.Flags().GetString("output")
+		summary, _ := cmd.Flags().GetBool("summary")
+		zones, err := getPrivacyZones(cmd)
+		if err != nil {
+			return err
+		}
+
+		if !summary && len(args) > 1 {
+			return fmt.Errorf("only one file can be exported (use --summary to export many)")
+		}
+		// summaries are exported as CSV only (`--format` defaults to GPX)
+		if summary && cmd.Flags().Changed("format") && format != export.FormatCSV {
+			return fmt.Errorf("summaries can be exported as %s only (not %q)", export.FormatCSV, format)
+		}
+		if !slices.Contains(export.Formats, format) {
+			return fmt.Errorf("unsupported format %q (supported: %s)", format, strings.Join(export.Formats, ", "))
+		}
+
+		if summary {
+			var acts []*common.Activity
+			for _, path := range args {
+				data, err := fit.ParseFile(path)
+				if err != nil {
+					return err
+				}
+				acts = append(acts, &common.Activity{
+					Path: path,
+					Data: asyncdata.NewSuccess[error](*data),
+				})
+			}
+			return writeOutput(cmd, output, func(w io.Writer) error {
+				return export.SummaryCSV(w, acts)
+			})
+		}
+
+		path := args[0]
+		data, err := fit.ParseFile(path)
+		if err != nil {
+			return err
+		}
+		return writeOutput(cmd, output, func(w io.Writer) error {
+			if err := export.Write(w, format, export.Name(path), *data, zones); err != nil {
+				return fmt.Errorf("failed to export %s: %v", path, err)
+			}
+			return nil
+		})
+	},
+}
+
+func init() {
+	exportCmd.Flags().StringP("format", "f", export.FormatGPX, "Format of the export ("+strings.Join(export.Formats, ", ")+"). CSV includes all records")
+	exportCmd.Flags().StringP("output", "o", "-", "File to write the export to ('-' for stdout)")
+	exportCmd.Flags().Bool("summary", false, "Export summaries of all given files as CSV (one row each)")
+	addPrivacyFlags(exportCmd)
+	rootCmd.AddCommand(exportCmd)
+}
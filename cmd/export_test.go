@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExportOutput(t *testing.T) {
+	dir := testActivitiesDir(t)
+	output := filepath.Join(t.TempDir(), "ride.csv")
+
+	if _, err := executeCmd(t, "export", "--format", "csv", "-o", output, filepath.Join(dir, "ride.fit")); err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// header and 10 records
+	if lines := strings.Split(strings.TrimSpace(string(content)), "\n"); len(lines) != 11 {
+		t.Errorf("Expected 11 lines, Got: %d", len(lines))
+	}
+}
+
+func TestOutputOfFailures(t *testing.T) {
+	dir := testActivitiesDir(t)
+	ride := filepath.Join(dir, "ride.fit")
+	broken := filepath.Join(dir, "broken.fit")
+
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"export", []string{"export", broken}},
+		// fails while writing: GPX of an activity without positions
+		{"export without positions", []string{"export", ride}},
+		{"export summaries", []string{"export", "--summary", ride, broken}},
+		{"merge", []string{"merge", ride, broken}},
+		{"anonymize", []string{"anonymize", broken}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outDir := t.TempDir()
+			output := filepath.Join(outDir, "existing")
+			if err := os.WriteFile(output, []byte("existing"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := executeCmd(t, append(tt.args, "-o", output)...); err == nil {
+				t.Fatal("Expected error, Got: nil")
+			}
+			// existing file is kept, no temporary files are left
+			if content, _ := os.ReadFile(output); string(content) != "existing" {
+				t.Errorf("Expected existing file to be kept, Got: %q", content)
+			}
+			if entries, _ := os.ReadDir(outDir); len(entries) != 1 {
+				t.Errorf("Expected 1 file, Got: %d", len(entries))
+			}
+		})
+	}
+}
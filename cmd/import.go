@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"sync"
+
+	"github.com/sectore/fit-activities-tui/internal/asyncdata"
+	"github.com/sectore/fit-activities-tui/internal/cache"
+	"github.com/sectore/fit-activities-tui/internal/common"
+	"github.com/sectore/fit-activities-tui/internal/fit"
+	"github.com/sectore/fit-activities-tui/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+// Adds flags to import and parse files (shared by all commands importing files)
+func addImportFlags(cmd *cobra.Command) {
+	cmd.Flags().StringArrayP("import", "i", nil, "Path to directory, single activity file (.fit, .gpx, .tcx, optionally compressed as .gz), archive (.zip) or glob patterns (e.g., '2025-11*.fit', 'dir/*ice*.fit', 'dir/**/*.fit'). Put path in quotes; use full paths (no shorthands). Can be used multiple times or passed as arguments")
+	cmd.Flags().BoolP("recursive", "r", false, "Import FIT files of sub directories, too")
+	cmd.Flags().StringArrayP("exclude", "e", nil, "Glob pattern of files or directories to exclude (e.g., '*backup*', 'dir/**/old/*'). Can be used multiple times")
+	cmd.Flags().IntP("jobs", "j", 0, "Number of FIT files to parse concurrently (0: number of CPUs)")
+}
+
+func addCacheFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("no-cache", false, "Disable cache of parsed FIT files")
+	cmd.Flags().String("cache-dir", "", "Directory to cache parsed FIT files (default: $XDG_CACHE_HOME/fit-activities-tui)")
+}
+
+// Import sources (`--import` flags and positional args) and import options
+func getImportSources(cmd *cobra.Command, args []string) ([]string, fit.ImportOptions, error) {
+	sources, _ := cmd.Flags().GetStringArray("import")
+	sources = append(sources, args...)
+	// if no path provided, try to use current directory
+	if len(sources) == 0 {
+		d, err := os.Getwd()
+		if err != nil {
+			return nil, fit.ImportOptions{}, fmt.Errorf("failed to get current working directory: %v", err)
+		}
+		sources = []string{d}
+	}
+
+	recursive, _ := cmd.Flags().GetBool("recursive")
+	exclude, _ := cmd.Flags().GetStringArray("exclude")
+	return sources, fit.ImportOptions{
+		Recursive: recursive,
+		Exclude:   exclude,
+	}, nil
+}
+
+// Number of files to parse concurrently
+func getJobs(cmd *cobra.Command) int {
+	jobs, _ := cmd.Flags().GetInt("jobs")
+	if jobs <= 0 {
+		jobs = runtime.NumCPU()
+	}
+	return jobs
+}
+
+// Cache stored in `--cache-dir` or default cache directory.
+// Returns `nil` if cache is disabled by `--no-cache`.
+func getCache(cmd *cobra.Command) (*cache.Cache, error) {
+	if noCache, _ := cmd.Flags().GetBool("no-cache"); noCache {
+		return nil, nil
+	}
+	dir, _ := cmd.Flags().GetString("cache-dir")
+	if dir == "" {
+		d, err := cache.DefaultDir()
+		if err != nil {
+			return nil, err
+		}
+		dir = d
+	}
+	return cache.New(dir)
+}
+
+// Imports and parses all files of given sources (concurrently),
+// sorted by `tui.DefaultActsSort`. Failures are part of the result.
+func importActivities(cmd *cobra.Command, args []string) (common.Activities, error) {
+	sources, importOpts, err := getImportSources(cmd, args)
+	if err != nil {
+		return nil, err
+	}
+	files, err := fit.GetImportFiles(sources, importOpts)
+	if err != nil {
+		return nil, err
+	}
+	c, err := getCache(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	showSources := tui.HasMultipleSources(files)
+	acts := make(common.Activities, len(files))
+	// limit number of files parsed concurrently
+	sem := make(chan struct{}, getJobs(cmd))
+	var wg sync.WaitGroup
+	for i, file := range files {
+		act := &common.Activity{Path: file.Path}
+		if showSources {
+			act.Source = file.Source
+		}
+		acts[i] = act
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			data, err := tui.ParseFile(act.Path, c)
+			if err != nil {
+				act.Data = asyncdata.NewFailure[error, common.ActivityData](err)
+				return
+			}
+			act.Data = asyncdata.NewSuccess[error](*data)
+		}()
+	}
+	wg.Wait()
+
+	tui.DefaultActsSort.Sort(acts)
+	return acts, nil
+}
@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/sectore/fit-activities-tui/internal/fit"
+	"github.com/spf13/cobra"
+)
+
+func printInspection(w io.Writer, index int, insp fit.Inspection) error {
+	h := insp.Header
+	fmt.Fprintf(w, "# FIT file %d\n\n", index+1)
+	rows := [][]string{
+		{"header size", fmt.Sprintf("%d", h.Size)},
+		{"protocol version", h.ProtocolVersion},
+		{"profile version", h.ProfileVersion},
+		{"data size", fmt.Sprintf("%d", h.DataSize)},
+		{"data type", h.DataType},
+		{"header crc", fmt.Sprintf("0x%04X", h.CRC)},
+	}
+	if insp.Error != "" {
+		rows = append(rows, []string{"error", insp.Error})
+	}
+	if err := printRows(w, rows); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "\n## Messages\n\n")
+	rows = [][]string{{"num", "message", "count"}}
+	for _, m := range insp.Messages {
+		rows = append(rows, []string{fmt.Sprintf("%d", m.Num), m.Message, fmt.Sprintf("%d", m.Count)})
+	}
+	if err := printRows(w, rows); err != nil {
+		return err
+	}
+
+	if len(insp.DeveloperData) > 0 {
+		fmt.Fprintf(w, "\n## Developer data\n\n")
+		rows = [][]string{{"index", "developer id", "application id", "version", "manufacturer"}}
+		for _, d := range insp.DeveloperData {
+			rows = append(rows, []string{
+				fmt.Sprintf("%d", d.Index),
+				d.DeveloperId,
+				d.ApplicationId,
+				fmt.Sprintf("%d", d.ApplicationVersion),
+				d.Manufacturer,
+			})
+		}
+		if err := printRows(w, rows); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(w, "\n## Definitions\n")
+	for _, def := range insp.Definitions {
+		fmt.Fprintf(w, "\n%s (num %d, local %d)\n", def.Message, def.Num, def.LocalNum)
+		rows = nil
+		for _, fd := range def.Fields {
+			rows = append(rows, []string{"", fmt.Sprintf("%d", fd.Num), fd.Name, fd.BaseType, fmt.Sprintf("%dB", fd.Size)})
+		}
+		for _, dfd := range def.DeveloperFields {
+			rows = append(rows, []string{"", fmt.Sprintf("dev %d:%d", dfd.DeveloperDataIndex, dfd.Num), dfd.Name, "", fmt.Sprintf("%dB", dfd.Size)})
+		}
+		if err := printRows(w, rows); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var inspectCmd = &cobra.Command{
+	Use:   "inspect <file>",
+	Short: "Print low level details of a FIT file (e.g. to debug files, which can't be parsed)",
+	Long: `Print low level details of a FIT file: file header, protocol and profile versions,
+counts of all messages, developer data and definitions of messages.
+Use --dump to print all messages as JSON lines instead (optionally filtered by --message).`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := args[0]
+		dump, _ := cmd.Flags().GetBool("dump")
+		messages, _ := cmd.Flags().GetStringArray("message")
+		if len(messages) > 0 && !dump {
+			return fmt.Errorf("--message requires --dump")
+		}
+		if dump {
+			for i, m := range messages {
+				messages[i] = strings.ToLower(strings.TrimSpace(m))
+			}
+			return fit.DumpMessages(cmd.OutOrStdout(), path, messages)
+		}
+
+		format, err := getFormat(cmd)
+		if err != nil {
+			return err
+		}
+		inspections, err := fit.Inspect(path)
+		if err != nil {
+			return err
+		}
+		if format == formatJSON {
+			return printJSON(cmd.OutOrStdout(), inspections)
+		}
+		for i, insp := range inspections {
+			if i > 0 {
+				fmt.Fprintln(cmd.OutOrStdout())
+			}
+			if err := printInspection(cmd.OutOrStdout(), i, insp); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	addFormatFlag(inspectCmd)
+	inspectCmd.Flags().Bool("dump", false, "Print all messages as JSON lines")
+	inspectCmd.Flags().StringArrayP("message", "m", []string{}, "Name of messages to dump (e.g. 'record', 'session'). Can be used multiple times")
+	rootCmd.AddCommand(inspectCmd)
+}
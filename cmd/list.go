@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"github.com/sectore/fit-activities-tui/internal/asyncdata"
+	"github.com/sectore/fit-activities-tui/internal/common"
+	"github.com/sectore/fit-activities-tui/internal/export"
+	"github.com/spf13/cobra"
+)
+
+// Activity of `list` (JSON)
+type listEntry struct {
+	export.Summary
+	Error string `json:"error,omitempty"`
+}
+
+var listCmd = &cobra.Command{
+	Use:   "list [paths...]",
+	Short: "Print one line per activity (latest first)",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, err := getFormat(cmd)
+		if err != nil {
+			return err
+		}
+		acts, err := importActivities(cmd, args)
+		if err != nil {
+			return err
+		}
+
+		if format == formatJSON {
+			entries := []listEntry{}
+			for _, act := range acts {
+				entry := listEntry{Summary: export.Summary{File: act.Path}}
+				if data, ok := asyncdata.Success(act.Data); ok {
+					entry.Summary = export.NewSummary(act.Path, *data)
+				}
+				if err, ok := asyncdata.Failure(act.Data); ok {
+					entry.Error = (*err).Error()
+				}
+				entries = append(entries, entry)
+			}
+			return printJSON(cmd.OutOrStdout(), entries)
+		}
+
+		var rows [][]string
+		for _, act := range acts {
+			if err, ok := asyncdata.Failure(act.Data); ok {
+				rows = append(rows, []string{
+					common.FailureMarker + " " + (*err).Error(), "", "", "", act.Path,
+				})
+				continue
+			}
+			rows = append(rows, []string{
+				act.Title(),
+				act.Sport().Format(),
+				act.TotalDistance().Format(),
+				act.GetTotalDuration().Format(),
+				act.Path,
+			})
+		}
+		return printRows(cmd.OutOrStdout(), rows)
+	},
+}
+
+func init() {
+	addImportFlags(listCmd)
+	addCacheFlags(listCmd)
+	addFormatFlag(listCmd)
+	rootCmd.AddCommand(listCmd)
+}
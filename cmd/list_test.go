@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestListText(t *testing.T) {
+	dir := testActivitiesDir(t)
+
+	out, err := executeCmd(t, "list", "--no-cache", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimRight(out, "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 lines, Got: %d (%q)", len(lines), out)
+	}
+
+	// latest first, failures last
+	tests := []struct {
+		line     string
+		expected []string
+	}{
+		{lines[0], []string{"02.06.25", "running", "20m", filepath.Join(dir, "run.fit")}},
+		{lines[1], []string{"01.06.25", "cycling", "45m", filepath.Join(dir, "ride.fit")}},
+		{lines[2], []string{"broken.fit", filepath.Join(dir, "broken.fit")}},
+	}
+	for _, tt := range tests {
+		for _, e := range tt.expected {
+			if !strings.Contains(tt.line, e) {
+				t.Errorf("Expected %q in line: %q", e, tt.line)
+			}
+		}
+	}
+}
+
+func TestListJSON(t *testing.T) {
+	dir := testActivitiesDir(t)
+
+	out, err := executeCmd(t, "list", "--no-cache", "--format", "json", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entries []listEntry
+	if err := json.Unmarshal([]byte(out), &entries); err != nil {
+		t.Fatalf("Expected JSON, Got: %q (%v)", out, err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("Expected 3 entries, Got: %d", len(entries))
+	}
+
+	tests := []struct {
+		entry     listEntry
+		file      string
+		sport     string
+		distanceM float64
+		failed    bool
+	}{
+		{entries[0], "run.fit", "running", 20, false},
+		{entries[1], "ride.fit", "cycling", 45, false},
+		{entries[2], "broken.fit", "", 0, true},
+	}
+	for _, tt := range tests {
+		if tt.entry.File != filepath.Join(dir, tt.file) {
+			t.Errorf("Expected file: %s, Got: %s", filepath.Join(dir, tt.file), tt.entry.File)
+		}
+		if tt.failed {
+			if tt.entry.Error == "" || tt.entry.DistanceM != nil {
+				t.Errorf("Expected failure of %s, Got: %+v", tt.file, tt.entry)
+			}
+			continue
+		}
+		if tt.entry.Error != "" {
+			t.Errorf("Expected no error of %s, Got: %s", tt.file, tt.entry.Error)
+		}
+		if tt.entry.Sport != tt.sport {
+			t.Errorf("Expected sport: %s, Got: %s", tt.sport, tt.entry.Sport)
+		}
+		if tt.entry.DistanceM == nil || *tt.entry.DistanceM != tt.distanceM {
+			t.Errorf("Expected distance: %vm, Got: %v", tt.distanceM, tt.entry.DistanceM)
+		}
+	}
+}
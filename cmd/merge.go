@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/muktihari/fit/profile/filedef"
+	"github.com/sectore/fit-activities-tui/internal/export"
+	"github.com/sectore/fit-activities-tui/internal/fit"
+	"github.com/spf13/cobra"
+)
+
+var mergeCmd = &cobra.Command{
+	Use:   "merge <files...>",
+	Short: "Merge FIT files (e.g. of a ride split by a battery swap) into one activity",
+	Args:  cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output, _ := cmd.Flags().GetString("output")
+		zones, err := getPrivacyZones(cmd)
+		if err != nil {
+			return err
+		}
+
+		var acts []*filedef.Activity
+		for _, path := range args {
+			act, err := fit.ReadActivity(path)
+			if err != nil {
+				return err
+			}
+			acts = append(acts, act)
+		}
+		merged, err := fit.MergeActivities(acts)
+		if err != nil {
+			return fmt.Errorf("failed to merge: %v", err)
+		}
+		fit.ApplyPrivacyZones(merged, zones)
+
+		if output == "" {
+			// new file next to the first file, never overwrites existing files
+			path, err := export.ActivityToFile(filepath.Dir(args[0]), export.Name(args[0])+"-merged", merged)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), path)
+			return nil
+		}
+
+		return writeOutput(cmd, output, func(w io.Writer) error {
+			if err := fit.WriteActivity(w, merged); err != nil {
+				return fmt.Errorf("failed to write merged activity: %v", err)
+			}
+			return nil
+		})
+	},
+}
+
+func init() {
+	mergeCmd.Flags().StringP("output", "o", "", "File to write the merged activity to ('-' for stdout). Defaults to a new file '<first file>-merged.fit'")
+	addPrivacyFlags(mergeCmd)
+	rootCmd.AddCommand(mergeCmd)
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+// Output formats of headless commands
+const (
+	formatText = "text"
+	formatJSON = "json"
+)
+
+func addFormatFlag(cmd *cobra.Command) {
+	cmd.Flags().StringP("format", "f", formatText, "Output format (text, json)")
+}
+
+func getFormat(cmd *cobra.Command) (string, error) {
+	format, _ := cmd.Flags().GetString("format")
+	if format != formatText && format != formatJSON {
+		return "", fmt.Errorf("unsupported format %q (supported: %s, %s)", format, formatText, formatJSON)
+	}
+	return format, nil
+}
+
+func printJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// Prints rows as aligned columns
+func printRows(w io.Writer, rows [][]string) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, row := range rows {
+		for i, col := range row {
+			if i > 0 {
+				fmt.Fprint(tw, "\t")
+			}
+			fmt.Fprint(tw, col)
+		}
+		fmt.Fprintln(tw)
+	}
+	return tw.Flush()
+}
+
+// Writes the output of a command to a file or to stdout ('-').
+// A temporary file is written first and renamed on success
+// to never leave a truncated or broken file (e.g. if writing fails).
+func writeOutput(cmd *cobra.Command, output string, write func(w io.Writer) error) error {
+	if output == "-" {
+		return write(cmd.OutOrStdout())
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(output), "."+filepath.Base(output)+"-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %v", output, err)
+	}
+	defer os.Remove(tmp.Name())
+	if err := write(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	// same permissions as of `os.Create` (temporary files are private)
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %v", output, err)
+	}
+	return os.Rename(tmp.Name(), output)
+}
@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"github.com/sectore/fit-activities-tui/internal/common"
+	"github.com/spf13/cobra"
+)
+
+func addPrivacyFlags(cmd *cobra.Command) {
+	cmd.Flags().StringArrayP("privacy-zone", "z", nil, "Privacy zone as 'lat,long,radius' (degrees, m), e.g. '52.52,13.405,500'. Positions inside of it are removed from exports: FIT files keep these records without positions, GPX and CSV files drop these records. Can be used multiple times")
+}
+
+func getPrivacyZones(cmd *cobra.Command) (common.PrivacyZones, error) {
+	values, _ := cmd.Flags().GetStringArray("privacy-zone")
+	var zones common.PrivacyZones
+	for _, value := range values {
+		zone, err := common.ParsePrivacyZone(value)
+		if err != nil {
+			return nil, err
+		}
+		zones = append(zones, zone)
+	}
+	return zones, nil
+}
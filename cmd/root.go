@@ -1,10 +1,12 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"os"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/sectore/fit-activities-tui/internal/fit"
@@ -13,19 +15,14 @@ import (
 )
 
 var rootCmd = &cobra.Command{
-	Use: "fit-activities-tui",
+	Use: "fit-activities-tui [paths...]",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		path, _ := cmd.Flags().GetString("import")
-		// if no path provided, try to use current directory
-		if path == "" {
-			d, err := os.Getwd()
-			if err != nil {
-				return fmt.Errorf("failed to get current working directory: %v", err)
-			}
-			path = d
+		sources, importOpts, err := getImportSources(cmd, args)
+		if err != nil {
+			return err
 		}
 
-		filePaths, err := fit.GetFitFilePaths(path)
+		files, err := fit.GetImportFiles(sources, importOpts)
 		if err != nil {
 			return fmt.Errorf("%v", err)
 		}
@@ -43,8 +40,33 @@ var rootCmd = &cobra.Command{
 			log.SetOutput(io.Discard)
 		}
 
+		jobs := getJobs(cmd)
+		c, err := getCache(cmd)
+		if err != nil {
+			return err
+		}
+
+		exportDir, _ := cmd.Flags().GetString("export-dir")
+		zones, err := getPrivacyZones(cmd)
+		if err != nil {
+			return err
+		}
+		opts := tui.Options{Jobs: jobs, Cache: c, ExportDir: exportDir, PrivacyZones: zones}
+		doWatch, _ := cmd.Flags().GetBool("watch")
+		if doWatch {
+			interval, _ := cmd.Flags().GetDuration("watch-interval")
+			if interval <= 0 {
+				return fmt.Errorf("invalid watch interval: %v", interval)
+			}
+			opts.Watch = &tui.WatchOptions{
+				Sources:       sources,
+				ImportOptions: importOpts,
+				Interval:      interval,
+			}
+		}
+
 		program := tea.NewProgram(
-			tui.InitialModel(filePaths),
+			tui.InitialModel(files, opts),
 			tea.WithAltScreen(),
 		)
 		_, err = program.Run()
@@ -57,13 +79,32 @@ var rootCmd = &cobra.Command{
 	},
 }
 
+// Error to exit with a specific code (e.g. to be handled by scripts)
+type exitError struct {
+	code int
+	msg  string
+}
+
+func (e exitError) Error() string {
+	return e.msg
+}
+
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
+		var exitErr exitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.code)
+		}
 		os.Exit(1)
 	}
 }
 
 func init() {
-	rootCmd.PersistentFlags().StringP("import", "i", "", "Path to directory or single FIT file or glob patterns (e.g., '2025-11*.fit', 'dir/*ice*.fit'). Put path in quotes; use full paths (no shorthands)")
-	rootCmd.PersistentFlags().Bool("log", false, "Enable logging to store logs into 'debug.log'")
+	addImportFlags(rootCmd)
+	addCacheFlags(rootCmd)
+	rootCmd.Flags().BoolP("watch", "w", false, "Watch imported paths for created, modified and deleted FIT files")
+	rootCmd.Flags().Duration("watch-interval", 2*time.Second, "Time between checks for changed files in watch mode")
+	rootCmd.Flags().String("export-dir", ".", "Directory to export activities to")
+	addPrivacyFlags(rootCmd)
+	rootCmd.Flags().Bool("log", false, "Enable logging to store logs into 'debug.log'")
 }
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/muktihari/fit/profile/typedef"
+	"github.com/sectore/fit-activities-tui/internal/fit/fittest"
+	"github.com/spf13/pflag"
+)
+
+// Directory of a ride (10 records), a run (5 records, one day later) and a broken FIT file
+func testActivitiesDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	fittest.WriteFile(t, filepath.Join(dir, "ride.fit"), fittest.Activity(10))
+	fittest.WriteFile(t, filepath.Join(dir, "run.fit"), fittest.ActivityAt(fittest.StartTime.Add(24*time.Hour), typedef.SportRunning, 5))
+	if err := os.WriteFile(filepath.Join(dir, "broken.fit"), []byte("no FIT file"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+// Runs a command (e.g. `list`) and returns its output.
+// Flags of all commands are shared by all tests, they are reset afterwards.
+func executeCmd(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	rootCmd.SetErr(io.Discard)
+	rootCmd.SetArgs(args)
+	cmd, err := rootCmd.ExecuteC()
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		if v, ok := f.Value.(pflag.SliceValue); ok {
+			v.Replace(nil)
+		} else {
+			f.Value.Set(f.DefValue)
+		}
+		f.Changed = false
+	})
+	return out.String(), err
+}
+
+// Rows of a text output (see `printRows`) by their labels (first column)
+func textRows(out string) map[string]string {
+	rows := map[string]string{}
+	for _, line := range strings.Split(out, "\n") {
+		label, value, ok := strings.Cut(line, "  ")
+		if ok {
+			rows[label] = strings.TrimSpace(value)
+		}
+	}
+	return rows
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/muktihari/fit/profile/filedef"
+	"github.com/sectore/fit-activities-tui/internal/export"
+	"github.com/sectore/fit-activities-tui/internal/fit"
+	"github.com/spf13/cobra"
+)
+
+var splitCmd = &cobra.Command{
+	Use:   "split <file>",
+	Short: "Split a FIT file (e.g. of a morning and an evening ride) into two activities",
+	Long: `Split a FIT file into two activities, written into new FIT files next to the original file
+('<file>-part1.fit', '<file>-part2.fit'). The original file is never changed.
+
+The activity is split at given record (--at) or at its longest pause (--min-pause).`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		at, _ := cmd.Flags().GetInt("at")
+		minPause, _ := cmd.Flags().GetDuration("min-pause")
+		zones, err := getPrivacyZones(cmd)
+		if err != nil {
+			return err
+		}
+
+		path := args[0]
+		act, err := fit.ReadActivity(path)
+		if err != nil {
+			return err
+		}
+		// records are numbered from 1
+		index := at - 1
+		if at <= 0 {
+			index, err = fit.SplitIndexAtPause(act, minPause)
+			if err != nil {
+				return fmt.Errorf("failed to split %s: %v", path, err)
+			}
+		}
+		first, second, err := fit.SplitActivity(act, index)
+		if err != nil {
+			return fmt.Errorf("failed to split %s: %v", path, err)
+		}
+
+		for i, part := range []*filedef.Activity{first, second} {
+			fit.ApplyPrivacyZones(part, zones)
+			name := fmt.Sprintf("%s-part%d", export.Name(path), i+1)
+			file, err := export.ActivityToFile(filepath.Dir(path), name, part)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), file)
+		}
+		return nil
+	},
+}
+
+func init() {
+	splitCmd.Flags().Int("at", 0, "Number of the first record of the second activity (e.g. shown by live data of the TUI)")
+	splitCmd.Flags().Duration("min-pause", fit.DefaultMinSplitPause, "Minimal pause to split at the longest pause (used without --at)")
+	addPrivacyFlags(splitCmd)
+	rootCmd.AddCommand(splitCmd)
+}
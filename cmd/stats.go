@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/sectore/fit-activities-tui/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+// Totals of activities (JSON)
+type stats struct {
+	Activities     int     `json:"activities"`
+	Parsed         int     `json:"parsed"`
+	Failed         int     `json:"failed"`
+	TotalDistanceM float64 `json:"total_distance_m"`
+	TotalDurationS float64 `json:"total_duration_s"`
+}
+
+var statsCmd = &cobra.Command{
+	Use:   "stats [paths...]",
+	Short: "Print totals of all activities (e.g. of a glob pattern)",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, err := getFormat(cmd)
+		if err != nil {
+			return err
+		}
+		acts, err := importActivities(cmd, args)
+		if err != nil {
+			return err
+		}
+
+		distance := tui.ActivitiesTotalDistances(acts)
+		duration := tui.ActivitiesTotalDuration(acts)
+		failed := tui.ActivitiesFailures(acts)
+
+		if format == formatJSON {
+			return printJSON(cmd.OutOrStdout(), stats{
+				Activities:     len(acts),
+				Parsed:         tui.ActivitiesParsed(acts),
+				Failed:         failed,
+				TotalDistanceM: float64(distance.Value) / 100,
+				TotalDurationS: float64(duration.Value) / 1000,
+			})
+		}
+
+		rows := [][]string{
+			{"activities", fmt.Sprintf("%d", len(acts))},
+			{"total time", duration.Format()},
+			{"total distance", distance.Format()},
+		}
+		if failed > 0 {
+			rows = append(rows, []string{"failed", fmt.Sprintf("%d", failed)})
+		}
+		return printRows(cmd.OutOrStdout(), rows)
+	},
+}
+
+func init() {
+	addImportFlags(statsCmd)
+	addCacheFlags(statsCmd)
+	addFormatFlag(statsCmd)
+	rootCmd.AddCommand(statsCmd)
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestStatsText(t *testing.T) {
+	dir := testActivitiesDir(t)
+
+	out, err := executeCmd(t, "stats", "--no-cache", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		label    string
+		expected string
+	}{
+		{"activities", "3"},
+		{"total time", "13s"},
+		{"total distance", "65m"},
+		{"failed", "1"},
+	}
+	rows := textRows(out)
+	for _, tt := range tests {
+		if rows[tt.label] != tt.expected {
+			t.Errorf("%s - Expected: %q, Got: %q", tt.label, tt.expected, rows[tt.label])
+		}
+	}
+}
+
+func TestStatsJSON(t *testing.T) {
+	dir := testActivitiesDir(t)
+
+	out, err := executeCmd(t, "stats", "--no-cache", "-f", "json", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result stats
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("Expected JSON, Got: %q (%v)", out, err)
+	}
+	expected := stats{
+		Activities:     3,
+		Parsed:         2,
+		Failed:         1,
+		TotalDistanceM: 65,
+		TotalDurationS: 13,
+	}
+	if result != expected {
+		t.Errorf("Expected: %+v, Got: %+v", expected, result)
+	}
+}
@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/sectore/fit-activities-tui/internal/common"
+	"github.com/sectore/fit-activities-tui/internal/export"
+	"github.com/sectore/fit-activities-tui/internal/fit"
+	"github.com/sectore/fit-activities-tui/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+// Formats an optional value or returns `common.NoDataText`
+func formatOr[A any](value *A, f func(A) string) string {
+	if value == nil {
+		return common.NoDataText
+	}
+	return f(*value)
+}
+
+// Source of the totals to compare recomputed totals with
+func totalsSource(ad common.ActivityData) string {
+	if ad.TotalsByRecords {
+		return "records"
+	}
+	return "device"
+}
+
+// Joins the texts of both columns of a row of the TUI details or returns `common.NoDataText`
+func joinTexts(first, second string, ok bool) string {
+	if !ok {
+		return common.NoDataText
+	}
+	return first + ", " + second
+}
+
+// Rows of the summary table (same as details of an activity in TUI).
+// Recomputed totals (optional) are shown next to the values of the device
+// (or the values calculated by records of GPX and TCX files).
+func summaryRows(path string, ad common.ActivityData, recomputed *common.RecomputedTotals) [][]string {
+	distanceTxt := formatOr(ad.TotalDistance, common.Distance.Format)
+	elevationTxt := joinTexts(tui.ElevationTexts(ad.Elevation))
+	if recomputed != nil {
+		distanceTxt = totalsSource(ad) + ": " + distanceTxt
+		elevationTxt = totalsSource(ad) + ": " + elevationTxt
+	}
+	distanceRow := []string{"distance", distanceTxt}
+	elevationRow := []string{"elevation", elevationTxt}
+	if recomputed != nil {
+		distanceRow = append(distanceRow, "recomputed: "+formatOr(recomputed.Distance, common.Distance.Format))
+		elevationRow = append(elevationRow, "recomputed: "+joinTexts(tui.ElevationTexts(recomputed.Elevation)))
+	}
+
+	rows := [][]string{
+		{"date", tui.DateText(ad)},
+		{"sport", ad.Sport.Format()},
+		distanceRow,
+		{"duration", formatOr(ad.Duration.Total, common.Duration.Format)},
+		{"active", joinTexts(tui.ActiveTexts(ad))},
+	}
+	// no speed for strength workouts
+	if !ad.Sport.IsStrength() {
+		rows = append(rows, []string{tui.SpeedLabel(ad.Sport), joinTexts(tui.SpeedTexts(ad))})
+	}
+	rows = append(rows, [][]string{
+		elevationRow,
+		{"temperature", joinTexts(tui.TemperatureTexts(ad))},
+		{"gps accuracy", joinTexts(tui.GpsAccuracyTexts(ad))},
+		{"♥ rate", joinTexts(tui.HeartrateTexts(ad))},
+		{"cadence", joinTexts(tui.CadenceTexts(ad))},
+		{"power", joinTexts(tui.PowerTexts(ad))},
+		{"laps", fmt.Sprintf("%d", len(ad.Laps))},
+		{"sessions", fmt.Sprintf("%d", ad.NoSessions)},
+		{"records", fmt.Sprintf("%d", ad.NoRecords())},
+		{"file", path},
+	}...)
+	return rows
+}
+
+// Summary including recomputed totals (JSON)
+type recomputedSummary struct {
+	export.Summary
+	TotalsSource string                   `json:"totals_source"`
+	Recomputed   export.RecomputedSummary `json:"recomputed"`
+}
+
+var summaryCmd = &cobra.Command{
+	Use:   "summary <file>",
+	Short: "Print the summary of an activity",
+	Long: `Print the summary of an activity.
+
+Totals reported by devices (e.g. ascents) may differ a lot for the same route.
+Use --recompute to show distance (by GPS positions) and ascents / descents
+(by smoothed altitudes) recalculated by records next to the values of the device.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, err := getFormat(cmd)
+		if err != nil {
+			return err
+		}
+		recompute, _ := cmd.Flags().GetBool("recompute")
+		threshold, _ := cmd.Flags().GetFloat64("elevation-threshold")
+		smoothing, _ := cmd.Flags().GetInt("smoothing")
+		if threshold < 0 || smoothing < 0 {
+			return fmt.Errorf("invalid options to recompute: elevation threshold %v, smoothing %d", threshold, smoothing)
+		}
+
+		path := args[0]
+		data, err := fit.ParseFile(path)
+		if err != nil {
+			return err
+		}
+
+		var recomputed *common.RecomputedTotals
+		if recompute {
+			opts := common.RecomputeOptions{ElevationThreshold: threshold, SmoothingWindow: smoothing}
+			recomputed = common.Ptr(common.RecomputeTotals(data.Records, opts))
+		}
+
+		if format == formatJSON {
+			summary := export.NewSummary(path, *data)
+			if recomputed != nil {
+				return printJSON(cmd.OutOrStdout(), recomputedSummary{summary, totalsSource(*data), export.NewRecomputedSummary(*recomputed)})
+			}
+			return printJSON(cmd.OutOrStdout(), summary)
+		}
+		return printRows(cmd.OutOrStdout(), summaryRows(path, *data, recomputed))
+	},
+}
+
+func init() {
+	addFormatFlag(summaryCmd)
+	defaults := common.DefaultRecomputeOptions()
+	summaryCmd.Flags().Bool("recompute", false, "Recalculate distance and ascents / descents by records and show them next to the values of the device")
+	summaryCmd.Flags().Float64("elevation-threshold", defaults.ElevationThreshold, "Altitude changes (m) below this threshold are ignored to recompute ascents / descents")
+	summaryCmd.Flags().Int("smoothing", defaults.SmoothingWindow, "Number of records to average altitudes to recompute ascents / descents (0: no smoothing)")
+	rootCmd.AddCommand(summaryCmd)
+}
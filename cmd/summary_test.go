@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+)
+
+func TestSummaryText(t *testing.T) {
+	dir := testActivitiesDir(t)
+	path := filepath.Join(dir, "ride.fit")
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected map[string]string
+	}{
+		{
+			name: "summary",
+			args: []string{"summary", path},
+			expected: map[string]string{
+				"date":      "01.06.25 08:00-08:00",
+				"sport":     "cycling",
+				"distance":  "45m",
+				"duration":  "9s",
+				"active":    "9s, (no pause)",
+				"speed":     "⌀ 18.0km/h, max 18.0km/h",
+				"elevation": "no data",
+				"♥ rate":    "⌀ 124bpm, max 129bpm",
+				"cadence":   "no data",
+				"records":   "10",
+				"file":      path,
+			},
+		},
+		{
+			name: "recomputed",
+			args: []string{"summary", "--recompute", path},
+			expected: map[string]string{
+				"distance":  "device: 45m  recomputed: no data",
+				"elevation": "device: no data  recomputed: no data",
+				"records":   "10",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := executeCmd(t, tt.args...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rows := textRows(out)
+			for label, expected := range tt.expected {
+				if rows[label] != expected {
+					t.Errorf("%s - Expected: %q, Got: %q", label, expected, rows[label])
+				}
+			}
+		})
+	}
+}
+
+func TestSummaryJSON(t *testing.T) {
+	dir := testActivitiesDir(t)
+	path := filepath.Join(dir, "ride.fit")
+
+	out, err := executeCmd(t, "summary", "--format", "json", "--recompute", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var summary recomputedSummary
+	if err := json.Unmarshal([]byte(out), &summary); err != nil {
+		t.Fatalf("Expected JSON, Got: %q (%v)", out, err)
+	}
+	if summary.File != path {
+		t.Errorf("Expected file: %s, Got: %s", path, summary.File)
+	}
+	if summary.DistanceM == nil || *summary.DistanceM != 45 {
+		t.Errorf("Expected distance: 45m, Got: %v", summary.DistanceM)
+	}
+	if summary.HeartrateMaxBpm == nil || *summary.HeartrateMaxBpm != 129 {
+		t.Errorf("Expected max. heartrate: 129, Got: %v", summary.HeartrateMaxBpm)
+	}
+	if summary.Records != 10 {
+		t.Errorf("Expected records: 10, Got: %d", summary.Records)
+	}
+	if summary.TotalsSource != "device" {
+		t.Errorf("Expected totals of device, Got: %s", summary.TotalsSource)
+	}
+	// no positions, no altitudes
+	if summary.Recomputed.DistanceM != nil || summary.Recomputed.AscentM != nil {
+		t.Errorf("Expected no recomputed totals, Got: %+v", summary.Recomputed)
+	}
+}
+
+func TestSummaryFailure(t *testing.T) {
+	dir := testActivitiesDir(t)
+
+	for _, format := range []string{formatText, formatJSON} {
+		out, err := executeCmd(t, "summary", "--format", format, filepath.Join(dir, "broken.fit"))
+		if err == nil {
+			t.Errorf("Expected error of a broken file (%s), Got: %q", format, out)
+		}
+	}
+}
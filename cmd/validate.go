@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/sectore/fit-activities-tui/internal/common"
+	"github.com/sectore/fit-activities-tui/internal/fit"
+	"github.com/spf13/cobra"
+)
+
+// Exit code of `validate` if any file is invalid
+const exitInvalid = 2
+
+// Report of `validate` (JSON)
+type validationReport struct {
+	Files       int              `json:"files"`
+	Invalid     int              `json:"invalid"`
+	Warnings    int              `json:"warnings"`
+	Validations []fit.Validation `json:"validations"`
+}
+
+func printValidations(w io.Writer, report validationReport) error {
+	for _, v := range report.Validations {
+		if len(v.Issues) == 0 {
+			continue
+		}
+		marker := "!"
+		if v.HasErrors() {
+			marker = common.FailureMarker
+		}
+		fmt.Fprintf(w, "%s %s\n", marker, v.File)
+		var rows [][]string
+		for _, issue := range v.Issues {
+			rows = append(rows, []string{"", string(issue.Severity), issue.Check, issue.Message})
+		}
+		if err := printRows(w, rows); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "%d files, %d invalid, %d with warnings\n", report.Files, report.Invalid, report.Warnings)
+	return err
+}
+
+var validateCmd = &cobra.Command{
+	Use:   "validate [paths...]",
+	Short: "Check activity files for corrupt data (CRC, structure) and implausible values",
+	Long: `Check activity files for corrupt data and implausible values:
+CRC of FIT files, boundaries of chained FIT files, missing sessions or records,
+speed spikes, heartrates above 250bpm and timestamps going backwards.
+
+Exit codes: 0 (all files valid), 1 (failed to run), 2 (invalid files found).
+Warnings of implausible values are handled as invalid files by --strict.`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, err := getFormat(cmd)
+		if err != nil {
+			return err
+		}
+		sources, importOpts, err := getImportSources(cmd, args)
+		if err != nil {
+			return err
+		}
+		files, err := fit.GetImportFiles(sources, importOpts)
+		if err != nil {
+			return err
+		}
+		strict, _ := cmd.Flags().GetBool("strict")
+
+		report := validationReport{
+			Files:       len(files),
+			Validations: make([]fit.Validation, len(files)),
+		}
+		// limit number of files validated concurrently
+		sem := make(chan struct{}, getJobs(cmd))
+		var wg sync.WaitGroup
+		for i, file := range files {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func() {
+				defer wg.Done()
+				defer func() { <-sem }()
+				report.Validations[i] = fit.Validate(file.Path)
+			}()
+		}
+		wg.Wait()
+
+		for _, v := range report.Validations {
+			if v.HasErrors() {
+				report.Invalid += 1
+			} else if v.HasWarnings() {
+				report.Warnings += 1
+			}
+		}
+
+		if format == formatJSON {
+			err = printJSON(cmd.OutOrStdout(), report)
+		} else {
+			err = printValidations(cmd.OutOrStdout(), report)
+		}
+		if err != nil {
+			return err
+		}
+
+		invalid := report.Invalid
+		if strict {
+			invalid += report.Warnings
+		}
+		if invalid > 0 {
+			return exitError{code: exitInvalid, msg: fmt.Sprintf("%d of %d files are invalid", invalid, report.Files)}
+		}
+		return nil
+	},
+}
+
+func init() {
+	addImportFlags(validateCmd)
+	addFormatFlag(validateCmd)
+	validateCmd.Flags().Bool("strict", false, "Handle warnings (implausible values) as invalid files")
+	rootCmd.AddCommand(validateCmd)
+}
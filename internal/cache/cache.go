@@ -0,0 +1,168 @@
+// Package cache stores parsed `ActivityData` on disk,
+// to avoid decoding unchanged FIT files again.
+package cache
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/sectore/fit-activities-tui/internal/common"
+	"github.com/sectore/fit-activities-tui/internal/fit"
+)
+
+// Version of the cache format.
+// Bump it whenever `common.ActivityData` or the way it's parsed changes
+// to invalidate all previously cached entries.
+const Version = 4
+
+const dirName = "fit-activities-tui"
+
+type entry struct {
+	Version int
+	Path    string
+	Size    int64
+	ModTime time.Time
+	// SHA-256 of file content
+	Hash string
+	Data common.ActivityData
+}
+
+type Cache struct {
+	dir string
+}
+
+// Default directory of the cache, e.g. `$XDG_CACHE_HOME/fit-activities-tui` on Linux
+func DefaultDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get cache directory: %v", err)
+	}
+	return filepath.Join(dir, dirName), nil
+}
+
+// Creates a new `Cache` stored in given directory
+func New(dir string) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %v", err)
+	}
+	return &Cache{dir: dir}, nil
+}
+
+// File of an entry, named by the hash of its (absolute) path
+func (c *Cache) entryFile(path string) string {
+	sum := sha256.Sum256([]byte(path))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".gob")
+}
+
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+func hashFile(path string) (string, error) {
+	f, err := fit.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func (c *Cache) read(path string) (*entry, error) {
+	content, err := os.ReadFile(c.entryFile(path))
+	if err != nil {
+		return nil, err
+	}
+	var e entry
+	if err := gob.NewDecoder(bytes.NewReader(content)).Decode(&e); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+func (c *Cache) write(e entry) error {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(e); err != nil {
+		return err
+	}
+	// write to a temporary file first to never leave a broken entry
+	tmp, err := os.CreateTemp(c.dir, "*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), c.entryFile(e.Path))
+}
+
+// Returns cached data of given file, if the file has not been changed.
+// Size and modification time are checked first. If they differ,
+// the content hash decides whether the cached data is still valid.
+func (c *Cache) Get(path string) (*common.ActivityData, bool) {
+	path = absPath(path)
+	info, err := fit.Stat(path)
+	if err != nil {
+		return nil, false
+	}
+
+	e, err := c.read(path)
+	if err != nil || e.Version != Version || e.Path != path {
+		return nil, false
+	}
+
+	if e.Size == info.Size() && e.ModTime.Equal(info.ModTime()) {
+		return &e.Data, true
+	}
+
+	// e.g. file has been touched or copied, but content is still the same
+	hash, err := hashFile(path)
+	if err != nil || hash != e.Hash {
+		return nil, false
+	}
+	// update meta data to skip hashing next time
+	e.Size = info.Size()
+	e.ModTime = info.ModTime()
+	_ = c.write(*e)
+
+	return &e.Data, true
+}
+
+// Stores data of given file
+func (c *Cache) Put(path string, data common.ActivityData) error {
+	path = absPath(path)
+	info, err := fit.Stat(path)
+	if err != nil {
+		return err
+	}
+	hash, err := hashFile(path)
+	if err != nil {
+		return err
+	}
+	return c.write(entry{
+		Version: Version,
+		Path:    path,
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+		Hash:    hash,
+		Data:    data,
+	})
+}
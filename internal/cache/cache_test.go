@@ -0,0 +1,68 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sectore/fit-activities-tui/internal/common"
+)
+
+func TestCache(t *testing.T) {
+	dir := t.TempDir()
+	c, err := New(filepath.Join(dir, "cache"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file := filepath.Join(dir, "activity.fit")
+	if err := os.WriteFile(file, []byte("content"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := c.Get(file); ok {
+		t.Fatal("Expected no entry for an unknown file")
+	}
+
+	start := common.NewTime(time.Date(2025, 6, 14, 10, 0, 0, 0, time.UTC))
+	data := common.ActivityData{
+		TotalDistance: common.Ptr(common.NewDistance(4200000)),
+		Records:       []common.RecordData{{Time: &start}},
+		Sport:         common.NewSport(common.SportCycling, "road"),
+	}
+	if err := c.Put(file, data); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("unchanged file", func(t *testing.T) {
+		cached, ok := c.Get(file)
+		if !ok {
+			t.Fatal("Expected cached entry")
+		}
+		if cached.TotalDistance.Value != data.TotalDistance.Value ||
+			cached.Sport != data.Sport ||
+			!cached.StartTime().Value.Equal(start.Value) {
+			t.Errorf("Expected: %+v, Got: %+v", data, cached)
+		}
+	})
+
+	t.Run("touched file with same content", func(t *testing.T) {
+		later := time.Now().Add(time.Hour)
+		if err := os.Chtimes(file, later, later); err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := c.Get(file); !ok {
+			t.Error("Expected cached entry")
+		}
+	})
+
+	t.Run("modified file", func(t *testing.T) {
+		if err := os.WriteFile(file, []byte("changed content"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := c.Get(file); ok {
+			t.Error("Expected no entry for a modified file")
+		}
+	})
+}
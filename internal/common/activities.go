@@ -2,7 +2,8 @@ package common
 
 import (
 	"fmt"
-	"sort"
+	"math"
+	"path/filepath"
 	"strings"
 
 	"time"
@@ -10,7 +11,12 @@ import (
 	"github.com/sectore/fit-activities-tui/internal/asyncdata"
 )
 
-const NoDataText = "no data"
+const (
+	NoDataText    = "no data"
+	BulletPoint   = "∙"
+	FailureMarker = "✗"
+	MarkedMarker  = "●"
+)
 
 // Helper to create a pointer to given value
 func Ptr[T any](v T) *T {
@@ -67,6 +73,17 @@ type GpsAccuracyStats struct {
 	Avg, Min, Max *GpsAccuracy
 }
 
+// Position in degrees
+type Position struct{ Lat, Long float64 }
+
+func NewPosition(lat, long float64) Position {
+	return Position{Lat: lat, Long: long}
+}
+
+func (p Position) Format() string {
+	return fmt.Sprintf("%.5f, %.5f", p.Lat, p.Long)
+}
+
 type Speed struct{ Value float32 }
 
 func NewSpeed(value float32) Speed {
@@ -81,6 +98,16 @@ type SpeedStats struct {
 	Avg, Max *Speed
 }
 
+// Formats `Speed` as pace (time per kilometer)
+func (s Speed) FormatPace() string {
+	if s.Value <= 0 {
+		return "-:--min/km"
+	}
+	// `Value` is stored in mm/s
+	secondsPerKm := int(math.Round(1000 * 1000 / float64(s.Value)))
+	return fmt.Sprintf("%d:%02dmin/km", secondsPerKm/60, secondsPerKm%60)
+}
+
 // Duration in milliseconds
 type Duration struct{ Value uint32 }
 
@@ -205,14 +232,136 @@ type HeartrateStats struct {
 	Min, Max, Avg *Heartrate
 }
 
+// Cadence in revolutions (or steps) per minute
+type Cadence struct{ Value uint8 }
+
+func NewCadence(value uint8) Cadence {
+	return Cadence{Value: value}
+}
+
+func (c Cadence) Format() string {
+	return fmt.Sprintf("%drpm", c.Value)
+}
+
+type CadenceStats struct {
+	// `Avg` ignores zero values (e.g. coasting), `AvgWithZeros` includes them
+	Min, Max, Avg, AvgWithZeros *Cadence
+}
+
+// Power in watts
+type Power struct{ Value uint16 }
+
+func NewPower(value uint16) Power {
+	return Power{Value: value}
+}
+
+func (p Power) Format() string {
+	return fmt.Sprintf("%dW", p.Value)
+}
+
+type PowerStats struct {
+	Avg, Max *Power
+	// Normalized power (NP): 4th root of the mean of the 4th powers of a 30s rolling average
+	Normalized *Power
+}
+
 type RecordData struct {
 	Time        *Time
 	Distance    *Distance
 	Speed       *Speed
 	Temperature *Temperature
 	GpsAccuracy *GpsAccuracy
+	Position    *Position
 	Altitude    *Altitude
 	Heartrate   *Heartrate
+	Power       *Power
+	Cadence     *Cadence
+}
+
+// Names of sports and sub sports as defined by the FIT profile
+const (
+	SportGeneric     = "generic"
+	SportRunning     = "running"
+	SportCycling     = "cycling"
+	SportSwimming    = "swimming"
+	SportWalking     = "walking"
+	SportHiking      = "hiking"
+	SportMultisport  = "multisport"
+	SubSportStrength = "strength_training"
+)
+
+type Sport struct {
+	Name    string
+	SubName string
+}
+
+func NewSport(name, subName string) Sport {
+	return Sport{Name: name, SubName: subName}
+}
+
+func (s Sport) Format() string {
+	name := s.Name
+	if name == "" {
+		name = SportGeneric
+	}
+	if s.SubName != "" && s.SubName != SportGeneric {
+		name += " " + BulletPoint + " " + s.SubName
+	}
+	return strings.ReplaceAll(name, "_", " ")
+}
+
+func (s Sport) Icon() string {
+	switch {
+	case s.IsStrength():
+		return "🏋"
+	case s.Name == SportRunning:
+		return "🏃"
+	case s.Name == SportCycling:
+		return "🚴"
+	case s.Name == SportSwimming:
+		return "🏊"
+	case s.Name == SportWalking:
+		return "🚶"
+	case s.Name == SportHiking:
+		return "🥾"
+	default:
+		return BulletPoint
+	}
+}
+
+// Running activities show pace instead of speed
+func (s Sport) IsRunning() bool {
+	return s.Name == SportRunning
+}
+
+// Strength workouts don't have any meaningful speed
+func (s Sport) IsStrength() bool {
+	return s.SubName == SubSportStrength
+}
+
+// Formats `Speed` depending on sport (pace for running, km/h for everything else)
+func (s Sport) FormatSpeed(speed Speed) string {
+	if s.IsRunning() {
+		return speed.FormatPace()
+	}
+	return speed.Format()
+}
+
+type LapData struct {
+	StartTime, FinishTime *Time
+	Duration              DurationStats
+	Distance              *Distance
+	Speed                 SpeedStats
+	Heartrate             HeartrateStats
+	Elevation             ElevationStats
+}
+
+// Checks whether given `Time` is part of the lap
+func (ld LapData) Contains(t Time) bool {
+	if ld.StartTime == nil || ld.FinishTime == nil {
+		return false
+	}
+	return !t.Value.Before(ld.StartTime.Value) && !t.Value.After(ld.FinishTime.Value)
 }
 
 type ActivityData struct {
@@ -226,6 +375,13 @@ type ActivityData struct {
 	Records       []RecordData
 	GpsAccuracy   GpsAccuracyStats
 	Heartrate     HeartrateStats
+	Power         PowerStats
+	Cadence       CadenceStats
+	Laps          []LapData
+	Sport         Sport
+	// Totals (e.g. elevation) are calculated by records (GPX, TCX)
+	// and not reported by the device (FIT)
+	TotalsByRecords bool
 }
 
 func (ad ActivityData) NoRecords() int {
@@ -247,24 +403,50 @@ func (ad ActivityData) FinishTime() *Time {
 	return ad.Records[last].Time
 }
 
+// Index of the lap the given `Record` belongs to.
+// Returns -1 if no lap has been found.
+func (ad ActivityData) LapIndex(record RecordData) int {
+	if record.Time == nil {
+		return -1
+	}
+	for i, lap := range ad.Laps {
+		if lap.Contains(*record.Time) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Checks whether at least one `Record` has a `Position`
+func (ad ActivityData) HasPositions() bool {
+	for _, r := range ad.Records {
+		if r.Position != nil {
+			return true
+		}
+	}
+	return false
+}
+
 type ActivityAD = asyncdata.AsyncData[error, ActivityData]
 
 type Activity struct {
 	Path string
+	// Root directory of the import source (empty if there is one source only)
+	Source string
 	/// index of current selected `Record`
 	recordIndex int
-	Data        ActivityAD
+	// marked to be merged with other activities
+	marked bool
+	Data   ActivityAD
 }
 
+// `FilterValue` contains all `FilterFields` of an activity (encoded),
+// which are evaluated by the filter query of the list.
 func (act Activity) FilterValue() string {
-	var value string
 	if data, ok := asyncdata.Success(act.Data); ok {
-		if startTime := data.StartTime(); startTime != nil {
-			value = startTime.Format()
-		}
+		return NewFilterFields(*data, act.Path).Encode()
 	}
-	return value
-
+	return ""
 }
 
 func (act Activity) Title() string {
@@ -274,11 +456,37 @@ func (act Activity) Title() string {
 			title = startTime.Format()
 		}
 	}
+	// failed activities have no data, show file name instead
+	if _, ok := asyncdata.Failure(act.Data); ok {
+		title = filepath.Base(act.Path)
+	}
+	if act.marked {
+		title = MarkedMarker + " " + title
+	}
 	return title
 }
 
 func (act Activity) Description() string {
-	return act.TotalDistance().Format()
+	if _, ok := asyncdata.Failure(act.Data); ok {
+		return FailureMarker + " failed to parse"
+	}
+	sport := act.Sport()
+	// strength workouts have no distance, show duration instead
+	desc := sport.Icon() + " " + act.TotalDistance().Format()
+	if sport.IsStrength() {
+		desc = sport.Icon() + " " + act.GetTotalDuration().Format()
+	}
+	if act.Source != "" {
+		desc += " " + BulletPoint + " " + filepath.Base(act.Source)
+	}
+	return desc
+}
+
+func (act Activity) Sport() Sport {
+	if data, ok := asyncdata.Success(act.Data); ok {
+		return data.Sport
+	}
+	return NewSport(SportGeneric, "")
 }
 
 func (act Activity) TotalDistance() Distance {
@@ -290,9 +498,6 @@ func (act Activity) TotalDistance() Distance {
 	return NewDistance(0)
 }
 
-// default time: January 1, 1970 UTC
-var defaultTime = time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC)
-
 func (act Activity) StartTime() *Time {
 	if data, ok := asyncdata.Success(act.Data); ok {
 		return data.StartTime()
@@ -345,6 +550,14 @@ func (act *Activity) ResetRecordIndex() {
 	act.recordIndex = 0
 }
 
+func (act *Activity) Marked() bool {
+	return act.marked
+}
+
+func (act *Activity) ToggleMarked() {
+	act.marked = !act.marked
+}
+
 // `Records` per second (RPS) based on total `Duration` and number of records
 func (act *Activity) RPS() float64 {
 	if data, ok := asyncdata.Success(act.Data); ok {
@@ -364,54 +577,3 @@ func (act *Activity) RPS() float64 {
 }
 
 type Activities = []*Activity // pointer slice to mutate values of `Activity`
-
-type SortBy func(act1, act2 *Activity) bool
-
-func (by SortBy) Sort(acts Activities) {
-	as := &actSorter{
-		acts: acts,
-		by:   by,
-	}
-	sort.Sort(as)
-}
-
-func (by SortBy) Reverse(acts Activities) {
-	as := &actSorter{
-		acts: acts,
-		by:   by,
-	}
-	sort.Sort(sort.Reverse(as))
-}
-
-type actSorter struct {
-	acts Activities
-	by   func(act1, act2 *Activity) bool
-}
-
-func (s *actSorter) Len() int {
-	return len(s.acts)
-}
-
-func (s *actSorter) Swap(i, j int) {
-	s.acts[i], s.acts[j] = s.acts[j], s.acts[i]
-}
-
-func (s *actSorter) Less(i, j int) bool {
-	return s.by(s.acts[i], s.acts[j])
-}
-
-var SortByDistance = func(act1, act2 *Activity) bool {
-	return act1.TotalDistance().Value < act2.TotalDistance().Value
-}
-
-var SortByTime = func(act1, act2 *Activity) bool {
-	t1 := defaultTime
-	if st1 := act1.StartTime(); st1 != nil {
-		t1 = st1.Value
-	}
-	t2 := defaultTime
-	if st2 := act2.StartTime(); st2 != nil {
-		t2 = st2.Value
-	}
-	return t1.Before(t2)
-}
@@ -0,0 +1,127 @@
+package common
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLapIndex(t *testing.T) {
+	start := time.Date(2025, 6, 1, 8, 0, 0, 0, time.UTC)
+	at := func(minutes int) *Time {
+		return Ptr(NewTime(start.Add(time.Duration(minutes) * time.Minute)))
+	}
+	lap := func(from, to int) LapData {
+		return LapData{StartTime: at(from), FinishTime: at(to)}
+	}
+	// pause between 2. and 3. lap
+	data := ActivityData{
+		Laps: []LapData{lap(0, 10), lap(10, 20), lap(25, 30), {StartTime: at(30)}},
+	}
+
+	tests := []struct {
+		name     string
+		record   RecordData
+		expected int
+	}{
+		{"start of first lap", RecordData{Time: at(0)}, 0},
+		{"inside of a lap", RecordData{Time: at(5)}, 0},
+		{"end of a lap (first lap wins)", RecordData{Time: at(10)}, 0},
+		{"inside of second lap", RecordData{Time: at(15)}, 1},
+		{"pause between laps", RecordData{Time: at(22)}, -1},
+		{"end of last lap", RecordData{Time: at(30)}, 2},
+		{"lap without finish", RecordData{Time: at(31)}, -1},
+		{"before first lap", RecordData{Time: at(-1)}, -1},
+		{"record without time", RecordData{}, -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := data.LapIndex(tt.record); got != tt.expected {
+				t.Errorf("Expected: %d, Got: %d", tt.expected, got)
+			}
+		})
+	}
+
+	if got := (ActivityData{}).LapIndex(RecordData{Time: at(0)}); got != -1 {
+		t.Errorf("Expected no lap of an activity without laps, Got: %d", got)
+	}
+}
+
+func TestSport(t *testing.T) {
+	tests := []struct {
+		sport      Sport
+		format     string
+		icon       string
+		isRunning  bool
+		isStrength bool
+	}{
+		{NewSport(SportRunning, ""), "running", "🏃", true, false},
+		{NewSport(SportRunning, "trail"), "running " + BulletPoint + " trail", "🏃", true, false},
+		{NewSport(SportCycling, SportGeneric), "cycling", "🚴", false, false},
+		{NewSport(SportCycling, "indoor_cycling"), "cycling " + BulletPoint + " indoor cycling", "🚴", false, false},
+		{NewSport("training", SubSportStrength), "training " + BulletPoint + " strength training", "🏋", false, true},
+		{NewSport(SportMultisport, ""), "multisport", BulletPoint, false, false},
+		{NewSport("", ""), "generic", BulletPoint, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			if got := tt.sport.Format(); got != tt.format {
+				t.Errorf("Format - Expected: %q, Got: %q", tt.format, got)
+			}
+			if got := tt.sport.Icon(); got != tt.icon {
+				t.Errorf("Icon - Expected: %q, Got: %q", tt.icon, got)
+			}
+			if got := tt.sport.IsRunning(); got != tt.isRunning {
+				t.Errorf("IsRunning - Expected: %v, Got: %v", tt.isRunning, got)
+			}
+			if got := tt.sport.IsStrength(); got != tt.isStrength {
+				t.Errorf("IsStrength - Expected: %v, Got: %v", tt.isStrength, got)
+			}
+		})
+	}
+}
+
+func TestFormatPace(t *testing.T) {
+	tests := []struct {
+		speed    Speed
+		expected string
+	}{
+		// 10 km/h
+		{NewSpeed(2777.78), "6:00min/km"},
+		// 12 km/h
+		{NewSpeed(3333.33), "5:00min/km"},
+		// 5 m/s
+		{NewSpeed(5000), "3:20min/km"},
+		// rounded to seconds: 1000 / 2.99 = 334.45s
+		{NewSpeed(2990), "5:34min/km"},
+		// slower than 1 hour per km
+		{NewSpeed(250), "66:40min/km"},
+		{NewSpeed(0), "-:--min/km"},
+		{NewSpeed(-1), "-:--min/km"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.speed.FormatPace(); got != tt.expected {
+			t.Errorf("%v - Expected: %s, Got: %s", tt.speed.Value, tt.expected, got)
+		}
+	}
+}
+
+func TestFormatSpeedBySport(t *testing.T) {
+	speed := NewSpeed(5000)
+	tests := []struct {
+		sport    Sport
+		expected string
+	}{
+		{NewSport(SportRunning, ""), "3:20min/km"},
+		{NewSport(SportCycling, ""), "18.0km/h"},
+		{NewSport(SportMultisport, ""), "18.0km/h"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.sport.FormatSpeed(speed); got != tt.expected {
+			t.Errorf("%s - Expected: %s, Got: %s", tt.sport.Name, tt.expected, got)
+		}
+	}
+}
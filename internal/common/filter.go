@@ -0,0 +1,170 @@
+package common
+
+import (
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Keys of `FilterFields`. Numeric values are stored in base units:
+// distances and elevations in meters, durations in seconds and speeds in km/h.
+const (
+	FilterKeyText        = "text"
+	FilterKeySport       = "sport"
+	FilterKeySubSport    = "subsport"
+	FilterKeyDate        = "date"
+	FilterKeyFile        = "file"
+	FilterKeyDistance    = "dist"
+	FilterKeyDuration    = "dur"
+	FilterKeyActive      = "active"
+	FilterKeyPause       = "pause"
+	FilterKeyAscent      = "ascent"
+	FilterKeyDescent     = "descent"
+	FilterKeyAltitudeMin = "alt.min"
+	FilterKeyAltitudeMax = "alt.max"
+	FilterKeySpeedAvg    = "speed.avg"
+	FilterKeySpeedMax    = "speed.max"
+	FilterKeyHrAvg       = "hr.avg"
+	FilterKeyHrMin       = "hr.min"
+	FilterKeyHrMax       = "hr.max"
+	FilterKeyCadenceAvg  = "cad.avg"
+	FilterKeyCadenceMax  = "cad.max"
+	FilterKeyPowerAvg    = "power.avg"
+	FilterKeyPowerMax    = "power.max"
+	FilterKeyPowerNp     = "power.np"
+	FilterKeyTempAvg     = "temp.avg"
+	FilterKeyTempMin     = "temp.min"
+	FilterKeyTempMax     = "temp.max"
+	FilterKeyRecords     = "records"
+	FilterKeyLaps        = "laps"
+	FilterKeySessions    = "sessions"
+)
+
+// Layout of `FilterKeyDate` values, sortable as string
+const FilterDateLayout = "2006-01-02 15:04"
+
+// `FilterFields` are all values of an `Activity` a filter can be applied to.
+// Missing values (no data) are not included.
+type FilterFields map[string]string
+
+func (ff FilterFields) setFloat(key string, value float64) {
+	ff[key] = strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// Encodes fields into a string of `key=value` lines (sorted by key)
+func (ff FilterFields) Encode() string {
+	keys := make([]string, 0, len(ff))
+	for key := range ff {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	lines := make([]string, len(keys))
+	for index, key := range keys {
+		// values are single lines only
+		value := strings.ReplaceAll(ff[key], "\n", " ")
+		lines[index] = key + "=" + value
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Decodes fields encoded by `FilterFields.Encode`
+func DecodeFilterFields(value string) FilterFields {
+	ff := FilterFields{}
+	for line := range strings.SplitSeq(value, "\n") {
+		if key, v, ok := strings.Cut(line, "="); ok {
+			ff[key] = v
+		}
+	}
+	return ff
+}
+
+// Creates `FilterFields` of given `ActivityData`
+func NewFilterFields(ad ActivityData, path string) FilterFields {
+	ff := FilterFields{}
+
+	file := filepath.Base(path)
+	ff[FilterKeyFile] = file
+	ff[FilterKeySport] = ad.Sport.Name
+	if ad.Sport.SubName != "" {
+		ff[FilterKeySubSport] = ad.Sport.SubName
+	}
+
+	// free text to search in
+	text := []string{file, ad.Sport.Name}
+	if startTime := ad.StartTime(); startTime != nil {
+		text = append(text, startTime.Format())
+		ff[FilterKeyDate] = startTime.Value.Format(FilterDateLayout)
+	}
+	ff[FilterKeyText] = strings.Join(text, " ")
+
+	if ad.TotalDistance != nil {
+		ff.setFloat(FilterKeyDistance, float64(ad.TotalDistance.Value)/100)
+	}
+	if ad.Duration.Total != nil {
+		ff.setFloat(FilterKeyDuration, float64(ad.Duration.Total.Value)/1000)
+	}
+	if ad.Duration.Active != nil {
+		ff.setFloat(FilterKeyActive, float64(ad.Duration.Active.Value)/1000)
+	}
+	if ad.Duration.Pause != nil {
+		ff.setFloat(FilterKeyPause, float64(ad.Duration.Pause.Value)/1000)
+	}
+	if ad.Elevation.Ascents != nil {
+		ff.setFloat(FilterKeyAscent, float64(ad.Elevation.Ascents.Value))
+	}
+	if ad.Elevation.Descents != nil {
+		ff.setFloat(FilterKeyDescent, float64(ad.Elevation.Descents.Value))
+	}
+	if ad.Altitude.Min != nil {
+		ff.setFloat(FilterKeyAltitudeMin, ad.Altitude.Min.Value)
+	}
+	if ad.Altitude.Max != nil {
+		ff.setFloat(FilterKeyAltitudeMax, ad.Altitude.Max.Value)
+	}
+	// speed in km/h
+	if ad.Speed.Avg != nil {
+		ff.setFloat(FilterKeySpeedAvg, float64(ad.Speed.Avg.Value)*3.6/1000)
+	}
+	if ad.Speed.Max != nil {
+		ff.setFloat(FilterKeySpeedMax, float64(ad.Speed.Max.Value)*3.6/1000)
+	}
+	if ad.Heartrate.Avg != nil {
+		ff.setFloat(FilterKeyHrAvg, float64(ad.Heartrate.Avg.Value))
+	}
+	if ad.Heartrate.Min != nil {
+		ff.setFloat(FilterKeyHrMin, float64(ad.Heartrate.Min.Value))
+	}
+	if ad.Heartrate.Max != nil {
+		ff.setFloat(FilterKeyHrMax, float64(ad.Heartrate.Max.Value))
+	}
+	if ad.Cadence.Avg != nil {
+		ff.setFloat(FilterKeyCadenceAvg, float64(ad.Cadence.Avg.Value))
+	}
+	if ad.Cadence.Max != nil {
+		ff.setFloat(FilterKeyCadenceMax, float64(ad.Cadence.Max.Value))
+	}
+	if ad.Power.Avg != nil {
+		ff.setFloat(FilterKeyPowerAvg, float64(ad.Power.Avg.Value))
+	}
+	if ad.Power.Max != nil {
+		ff.setFloat(FilterKeyPowerMax, float64(ad.Power.Max.Value))
+	}
+	if ad.Power.Normalized != nil {
+		ff.setFloat(FilterKeyPowerNp, float64(ad.Power.Normalized.Value))
+	}
+	if ad.Temperature.Avg != nil {
+		ff.setFloat(FilterKeyTempAvg, float64(ad.Temperature.Avg.Value))
+	}
+	if ad.Temperature.Min != nil {
+		ff.setFloat(FilterKeyTempMin, float64(ad.Temperature.Min.Value))
+	}
+	if ad.Temperature.Max != nil {
+		ff.setFloat(FilterKeyTempMax, float64(ad.Temperature.Max.Value))
+	}
+	ff.setFloat(FilterKeyRecords, float64(ad.NoRecords()))
+	ff.setFloat(FilterKeyLaps, float64(len(ad.Laps)))
+	ff.setFloat(FilterKeySessions, float64(ad.NoSessions))
+
+	return ff
+}
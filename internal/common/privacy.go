@@ -0,0 +1,70 @@
+package common
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Area around a location (e.g. home), which shouldn't be part of shared activities
+type PrivacyZone struct {
+	Center Position
+	// Radius in m
+	Radius float64
+}
+
+// Parses a privacy zone of format `lat,long,radius` (degrees and m), e.g. `52.52,13.405,500`
+func ParsePrivacyZone(value string) (PrivacyZone, error) {
+	parts := strings.Split(value, ",")
+	if len(parts) != 3 {
+		return PrivacyZone{}, fmt.Errorf("invalid privacy zone %q (expected: lat,long,radius)", value)
+	}
+	var values [3]float64
+	for i, part := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return PrivacyZone{}, fmt.Errorf("invalid privacy zone %q: %v", value, err)
+		}
+		values[i] = v
+	}
+	lat, long, radius := values[0], values[1], values[2]
+	if lat < -90 || lat > 90 || long < -180 || long > 180 {
+		return PrivacyZone{}, fmt.Errorf("invalid privacy zone %q: position out of range", value)
+	}
+	if radius <= 0 {
+		return PrivacyZone{}, fmt.Errorf("invalid privacy zone %q: radius must be greater than 0", value)
+	}
+	return PrivacyZone{Center: NewPosition(lat, long), Radius: radius}, nil
+}
+
+func (pz PrivacyZone) Contains(p Position) bool {
+	return pz.Center.DistanceTo(p) <= pz.Radius
+}
+
+type PrivacyZones []PrivacyZone
+
+// Checks whether given `Position` is part of any zone
+func (pzs PrivacyZones) Contains(p Position) bool {
+	for _, pz := range pzs {
+		if pz.Contains(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// Drops all records with a `Position` inside of any zone.
+// Records without a `Position` are kept.
+func (pzs PrivacyZones) FilterRecords(records []RecordData) []RecordData {
+	if len(pzs) == 0 {
+		return records
+	}
+	var filtered []RecordData
+	for _, r := range records {
+		if r.Position != nil && pzs.Contains(*r.Position) {
+			continue
+		}
+		filtered = append(filtered, r)
+	}
+	return filtered
+}
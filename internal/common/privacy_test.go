@@ -0,0 +1,60 @@
+package common
+
+import "testing"
+
+func TestParsePrivacyZone(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected *PrivacyZone
+	}{
+		{"52.52,13.405,500", &PrivacyZone{Center: NewPosition(52.52, 13.405), Radius: 500}},
+		{" 52.52, 13.405, 500 ", &PrivacyZone{Center: NewPosition(52.52, 13.405), Radius: 500}},
+		{"52.52,13.405", nil},
+		{"52.52,13.405,abc", nil},
+		{"95,13.405,500", nil},
+		{"52.52,13.405,0", nil},
+	}
+	for _, test := range tests {
+		pz, err := ParsePrivacyZone(test.value)
+		if test.expected == nil {
+			if err == nil {
+				t.Errorf("%q: Expected error, Got: %+v", test.value, pz)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: Expected no error, Got: %v", test.value, err)
+			continue
+		}
+		if pz != *test.expected {
+			t.Errorf("%q: Expected: %+v, Got: %+v", test.value, *test.expected, pz)
+		}
+	}
+}
+
+func TestPrivacyZonesFilterRecords(t *testing.T) {
+	zones := PrivacyZones{{Center: NewPosition(52.52, 13.4), Radius: 100}}
+	records := []RecordData{
+		// ~0m
+		{Position: Ptr(NewPosition(52.52, 13.4))},
+		// ~56m
+		{Position: Ptr(NewPosition(52.5205, 13.4))},
+		// ~111m
+		{Position: Ptr(NewPosition(52.521, 13.4))},
+		// no position
+		{Heartrate: Ptr(NewHeartrate(120))},
+	}
+	filtered := zones.FilterRecords(records)
+	if len(filtered) != 2 {
+		t.Fatalf("Expected 2 records, Got: %d", len(filtered))
+	}
+	if filtered[0].Position == nil || filtered[0].Position.Lat != 52.521 {
+		t.Errorf("Expected record outside of zone, Got: %v", filtered[0].Position)
+	}
+	if filtered[1].Heartrate == nil {
+		t.Errorf("Expected record without position")
+	}
+	if got := PrivacyZones(nil).FilterRecords(records); len(got) != len(records) {
+		t.Errorf("Expected all records without zones, Got: %d", len(got))
+	}
+}
@@ -0,0 +1,206 @@
+package common
+
+import (
+	"cmp"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/sectore/fit-activities-tui/internal/asyncdata"
+)
+
+// Field to sort activities by
+type SortField int
+
+const (
+	SortFieldTime SortField = iota
+	SortFieldDistance
+	SortFieldDuration
+	SortFieldActive
+	SortFieldAscent
+	SortFieldSpeed
+	SortFieldHeartrate
+	SortFieldTemperature
+	SortFieldRecords
+	SortFieldFile
+)
+
+// All `SortField`s in order of appearance (e.g. in a menu)
+var SortFields = []SortField{
+	SortFieldTime,
+	SortFieldDistance,
+	SortFieldDuration,
+	SortFieldActive,
+	SortFieldAscent,
+	SortFieldSpeed,
+	SortFieldHeartrate,
+	SortFieldTemperature,
+	SortFieldRecords,
+	SortFieldFile,
+}
+
+func (f SortField) Label() string {
+	switch f {
+	case SortFieldTime:
+		return "time"
+	case SortFieldDistance:
+		return "distance"
+	case SortFieldDuration:
+		return "duration"
+	case SortFieldActive:
+		return "active time"
+	case SortFieldAscent:
+		return "ascent"
+	case SortFieldSpeed:
+		return "⌀ speed"
+	case SortFieldHeartrate:
+		return "⌀ ♥ rate"
+	case SortFieldTemperature:
+		return "max temperature"
+	case SortFieldRecords:
+		return "records"
+	case SortFieldFile:
+		return "file"
+	default:
+		return ""
+	}
+}
+
+// Numeric value of an activity to sort by.
+// Returns `false` if the activity has no such value (e.g. no heart rate data).
+func (f SortField) value(act *Activity) (float64, bool) {
+	data, ok := asyncdata.Success(act.Data)
+	if !ok {
+		return 0, false
+	}
+	switch f {
+	case SortFieldTime:
+		if startTime := data.StartTime(); startTime != nil {
+			return float64(startTime.Value.Unix()), true
+		}
+	case SortFieldDistance:
+		if data.TotalDistance != nil {
+			return float64(data.TotalDistance.Value), true
+		}
+	case SortFieldDuration:
+		if data.Duration.Total != nil {
+			return float64(data.Duration.Total.Value), true
+		}
+	case SortFieldActive:
+		if data.Duration.Active != nil {
+			return float64(data.Duration.Active.Value), true
+		}
+	case SortFieldAscent:
+		if data.Elevation.Ascents != nil {
+			return float64(data.Elevation.Ascents.Value), true
+		}
+	case SortFieldSpeed:
+		if data.Speed.Avg != nil {
+			return float64(data.Speed.Avg.Value), true
+		}
+	case SortFieldHeartrate:
+		if data.Heartrate.Avg != nil {
+			return float64(data.Heartrate.Avg.Value), true
+		}
+	case SortFieldTemperature:
+		if data.Temperature.Max != nil {
+			return float64(data.Temperature.Max.Value), true
+		}
+	case SortFieldRecords:
+		return float64(data.NoRecords()), true
+	}
+	return 0, false
+}
+
+// Compares two activities by field (ascending).
+// Activities without a value are treated as lower than any other value.
+func (f SortField) compare(act1, act2 *Activity) int {
+	if f == SortFieldFile {
+		return strings.Compare(
+			strings.ToLower(filepath.Base(act1.Path)),
+			strings.ToLower(filepath.Base(act2.Path)),
+		)
+	}
+	v1, ok1 := f.value(act1)
+	v2, ok2 := f.value(act2)
+	switch {
+	case !ok1 && !ok2:
+		return 0
+	case !ok1:
+		return -1
+	case !ok2:
+		return 1
+	default:
+		return cmp.Compare(v1, v2)
+	}
+}
+
+type SortKey struct {
+	Field SortField
+	Desc  bool
+}
+
+func (k SortKey) Compare(act1, act2 *Activity) int {
+	c := k.Field.compare(act1, act2)
+	if k.Desc {
+		return -c
+	}
+	return c
+}
+
+// Sort keys, ordered by priority: first key is the primary key,
+// all following keys are used to sort activities with equal values.
+type SortKeys []SortKey
+
+// Sorts activities in place (stable)
+func (keys SortKeys) Sort(acts Activities) {
+	slices.SortStableFunc(acts, keys.Compare)
+}
+
+func (keys SortKeys) Compare(act1, act2 *Activity) int {
+	for _, key := range keys {
+		if c := key.Compare(act1, act2); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// Index of given field in keys. Returns -1 if keys don't include the field.
+func (keys SortKeys) Index(field SortField) int {
+	return slices.IndexFunc(keys, func(k SortKey) bool {
+		return k.Field == field
+	})
+}
+
+// Returns new keys sorted by given field only.
+// The direction is toggled if the field is already the primary key.
+func (keys SortKeys) WithPrimary(field SortField) SortKeys {
+	// descending by default, which is more useful for most fields (latest, longest, ...)
+	desc := true
+	if len(keys) > 0 && keys[0].Field == field {
+		desc = !keys[0].Desc
+	}
+	return SortKeys{{Field: field, Desc: desc}}
+}
+
+// Returns new keys with given field added as a secondary key.
+// The direction is toggled if the field is already part of the keys.
+func (keys SortKeys) WithSecondary(field SortField) SortKeys {
+	next := slices.Clone(keys)
+	if index := next.Index(field); index >= 0 {
+		next[index].Desc = !next[index].Desc
+		return next
+	}
+	return append(next, SortKey{Field: field, Desc: true})
+}
+
+// Returns new keys without given field. The last key can't be removed.
+func (keys SortKeys) Without(field SortField) SortKeys {
+	if len(keys) <= 1 {
+		return keys
+	}
+	return slices.DeleteFunc(slices.Clone(keys), func(k SortKey) bool {
+		return k.Field == field
+	})
+}
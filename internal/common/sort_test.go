@@ -0,0 +1,126 @@
+package common
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sectore/fit-activities-tui/internal/asyncdata"
+)
+
+func newSortActivity(path string, start time.Time, distance uint32, heartrate *uint8) *Activity {
+	t := NewTime(start)
+	data := ActivityData{
+		TotalDistance: Ptr(NewDistance(distance)),
+		Records:       []RecordData{{Time: &t}},
+	}
+	if heartrate != nil {
+		data.Heartrate.Avg = Ptr(NewHeartrate(*heartrate))
+	}
+	return &Activity{
+		Path: path,
+		Data: asyncdata.NewSuccess[error](data),
+	}
+}
+
+func paths(acts Activities) []string {
+	result := make([]string, len(acts))
+	for i, act := range acts {
+		result[i] = act.Path
+	}
+	return result
+}
+
+func TestSortKeys(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2025, 6, d, 10, 0, 0, 0, time.UTC)
+	}
+	a := newSortActivity("a.fit", day(1), 5000, Ptr(uint8(140)))
+	b := newSortActivity("B.fit", day(2), 3000, nil)
+	c := newSortActivity("c.fit", day(3), 5000, Ptr(uint8(120)))
+	failed := &Activity{Path: "failed.fit", Data: asyncdata.NewFailure[error, ActivityData](nil)}
+
+	tests := []struct {
+		name     string
+		keys     SortKeys
+		expected []string
+	}{
+		{
+			name:     "time desc",
+			keys:     SortKeys{{Field: SortFieldTime, Desc: true}},
+			expected: []string{"c.fit", "B.fit", "a.fit", "failed.fit"},
+		},
+		{
+			name:     "time asc",
+			keys:     SortKeys{{Field: SortFieldTime}},
+			expected: []string{"failed.fit", "a.fit", "B.fit", "c.fit"},
+		},
+		{
+			name:     "distance desc, then time asc",
+			keys:     SortKeys{{Field: SortFieldDistance, Desc: true}, {Field: SortFieldTime}},
+			expected: []string{"a.fit", "c.fit", "B.fit", "failed.fit"},
+		},
+		{
+			name:     "distance desc, then time desc",
+			keys:     SortKeys{{Field: SortFieldDistance, Desc: true}, {Field: SortFieldTime, Desc: true}},
+			expected: []string{"c.fit", "a.fit", "B.fit", "failed.fit"},
+		},
+		{
+			name:     "heart rate asc, missing values first",
+			keys:     SortKeys{{Field: SortFieldHeartrate}, {Field: SortFieldTime}},
+			expected: []string{"failed.fit", "B.fit", "c.fit", "a.fit"},
+		},
+		{
+			name:     "file name (case insensitive)",
+			keys:     SortKeys{{Field: SortFieldFile}},
+			expected: []string{"a.fit", "B.fit", "c.fit", "failed.fit"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			acts := Activities{b, failed, a, c}
+			tt.keys.Sort(acts)
+			result := paths(acts)
+			for i := range tt.expected {
+				if result[i] != tt.expected[i] {
+					t.Errorf("Expected: %v, Got: %v", tt.expected, result)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestSortKeysUpdates(t *testing.T) {
+	keys := SortKeys{{Field: SortFieldTime, Desc: true}}
+
+	keys = keys.WithPrimary(SortFieldTime)
+	if len(keys) != 1 || keys[0].Desc {
+		t.Errorf("Expected time asc, Got: %v", keys)
+	}
+
+	keys = keys.WithSecondary(SortFieldDistance)
+	if len(keys) != 2 || keys[1].Field != SortFieldDistance || !keys[1].Desc {
+		t.Errorf("Expected time asc, distance desc, Got: %v", keys)
+	}
+
+	keys = keys.WithSecondary(SortFieldDistance)
+	if len(keys) != 2 || keys[1].Desc {
+		t.Errorf("Expected distance direction to be toggled, Got: %v", keys)
+	}
+
+	keys = keys.Without(SortFieldTime)
+	if len(keys) != 1 || keys[0].Field != SortFieldDistance {
+		t.Errorf("Expected distance only, Got: %v", keys)
+	}
+
+	keys = keys.Without(SortFieldDistance)
+	if len(keys) != 1 {
+		t.Errorf("Expected last key not to be removed, Got: %v", keys)
+	}
+
+	keys = keys.WithPrimary(SortFieldAscent)
+	if len(keys) != 1 || keys[0].Field != SortFieldAscent || !keys[0].Desc {
+		t.Errorf("Expected ascent desc, Got: %v", keys)
+	}
+}
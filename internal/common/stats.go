@@ -0,0 +1,452 @@
+package common
+
+import (
+	"math"
+	"time"
+)
+
+// Mean radius of the earth in meters
+const earthRadius = 6371008.8
+
+// Great-circle distance (haversine) between two positions in meters
+func (p Position) DistanceTo(other Position) float64 {
+	lat1 := p.Lat * math.Pi / 180
+	lat2 := other.Lat * math.Pi / 180
+	dLat := lat2 - lat1
+	dLong := (other.Long - p.Long) * math.Pi / 180
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLong/2)*math.Sin(dLong/2)
+	return 2 * earthRadius * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// Altitude changes below this threshold (in meters) are ignored to calculate ascents and descents
+const ElevationThreshold = 3.0
+
+// Gaps between two records longer than this are handled as pauses
+const PauseThreshold = 10 * time.Second
+
+// Calculates ascents and descents of all `Altitude` values of given records.
+// Changes are counted as soon as they exceed given threshold (hysteresis),
+// which ignores small fluctuations (e.g. noise of GPS altitudes).
+func ElevationOfRecords(records []RecordData, threshold float64) ElevationStats {
+	var stats ElevationStats
+	var ref *float64
+	var ascents, descents float64
+	for _, r := range records {
+		if r.Altitude == nil {
+			continue
+		}
+		altitude := r.Altitude.Value
+		if ref == nil {
+			ref = &altitude
+			continue
+		}
+		diff := altitude - *ref
+		if diff >= threshold {
+			ascents += diff
+			ref = &altitude
+		} else if -diff >= threshold {
+			descents -= diff
+			ref = &altitude
+		}
+	}
+	if ref != nil {
+		stats.Ascents = Ptr(NewElevation(uint16(math.Round(ascents))))
+		stats.Descents = Ptr(NewElevation(uint16(math.Round(descents))))
+	}
+	return stats
+}
+
+// Calculates total, active and pause duration of given records.
+// Gaps longer than `PauseThreshold` are counted as pause.
+func DurationOfRecords(records []RecordData) DurationStats {
+	var stats DurationStats
+	var first, prev *time.Time
+	var pause time.Duration
+	for _, r := range records {
+		if r.Time == nil {
+			continue
+		}
+		t := r.Time.Value
+		if first == nil {
+			first = &t
+		}
+		if prev != nil {
+			if gap := t.Sub(*prev); gap > PauseThreshold {
+				pause += gap
+			}
+		}
+		prev = &t
+	}
+	if first == nil {
+		return stats
+	}
+	total := prev.Sub(*first)
+	stats.Total = Ptr(NewDuration(uint32(total.Milliseconds())))
+	stats.Active = Ptr(NewDuration(uint32((total - pause).Milliseconds())))
+	stats.Pause = Ptr(NewDuration(uint32(pause.Milliseconds())))
+	return stats
+}
+
+// Number of records to smooth altitudes by default (see `SmoothAltitudes`)
+const DefaultSmoothingWindow = 5
+
+// Options to recalculate totals by records (see `RecomputeTotals`)
+type RecomputeOptions struct {
+	// altitude changes below this threshold (in meters) are ignored (hysteresis)
+	ElevationThreshold float64
+	// number of records to average altitudes (0 or 1: no smoothing)
+	SmoothingWindow int
+}
+
+func DefaultRecomputeOptions() RecomputeOptions {
+	return RecomputeOptions{
+		ElevationThreshold: ElevationThreshold,
+		SmoothingWindow:    DefaultSmoothingWindow,
+	}
+}
+
+// Totals recalculated by raw values of records, e.g. to compare them with values reported by a device
+type RecomputedTotals struct {
+	// by positions (haversine), `nil` if there are no positions
+	Distance  *Distance
+	Elevation ElevationStats
+}
+
+// Recalculates distance by positions and ascents / descents by (smoothed) altitudes of records.
+// Values reported by a device (e.g. distances of records) are ignored.
+func RecomputeTotals(records []RecordData, opts RecomputeOptions) RecomputedTotals {
+	return RecomputedTotals{
+		Distance:  DistanceOfPositions(records),
+		Elevation: ElevationOfRecords(SmoothAltitudes(records, opts.SmoothingWindow), opts.ElevationThreshold),
+	}
+}
+
+// Sum of distances (haversine) between positions of given records.
+// Returns `nil` if there are less than 2 positions.
+func DistanceOfPositions(records []RecordData) *Distance {
+	var total float64
+	var prev *Position
+	count := 0
+	for _, r := range records {
+		if r.Position == nil {
+			continue
+		}
+		if prev != nil {
+			total += prev.DistanceTo(*r.Position)
+		}
+		prev = r.Position
+		count += 1
+	}
+	if count < 2 {
+		return nil
+	}
+	return Ptr(NewDistance(uint32(math.Round(total * 100))))
+}
+
+// Copy of records with altitudes smoothed by a centered moving average of `window` records.
+// Records without altitudes are ignored (and kept as they are).
+func SmoothAltitudes(records []RecordData, window int) []RecordData {
+	smoothed := make([]RecordData, len(records))
+	copy(smoothed, records)
+	if window <= 1 {
+		return smoothed
+	}
+
+	var indexes []int
+	for i, r := range records {
+		if r.Altitude != nil {
+			indexes = append(indexes, i)
+		}
+	}
+	half := window / 2
+	for n, i := range indexes {
+		from := max(0, n-half)
+		to := min(len(indexes)-1, n+(window-1-half))
+		var sum float64
+		for _, j := range indexes[from : to+1] {
+			sum += records[j].Altitude.Value
+		}
+		smoothed[i].Altitude = Ptr(NewAltitude(sum / float64(to-from+1)))
+	}
+	return smoothed
+}
+
+// Time window to calculate speed of records by distances
+const speedWindow = 5 * time.Second
+
+// Adds `Distance` (by positions) to records without distances (e.g. GPX).
+// Existing distances are kept.
+func AddDistances(records []RecordData) {
+	for _, r := range records {
+		if r.Distance != nil {
+			return
+		}
+	}
+	var total float64
+	var prev *Position
+	for i, r := range records {
+		if r.Position == nil {
+			continue
+		}
+		if prev != nil {
+			total += prev.DistanceTo(*r.Position)
+		}
+		prev = r.Position
+		records[i].Distance = Ptr(NewDistance(uint32(math.Round(total * 100))))
+	}
+}
+
+func hasTimeAndDistance(r RecordData) bool {
+	return r.Time != nil && r.Distance != nil
+}
+
+// Adds `Speed` (by distances over a short time window) to records without speed.
+// Existing speed values are kept.
+func AddSpeeds(records []RecordData) {
+	start := 0
+	for i, r := range records {
+		if r.Speed != nil || !hasTimeAndDistance(r) {
+			continue
+		}
+		// move start of the window to the latest record at least `speedWindow` before
+		for start < i-1 && (!hasTimeAndDistance(records[start]) ||
+			!hasTimeAndDistance(records[start+1]) ||
+			r.Time.Value.Sub(records[start+1].Time.Value) >= speedWindow) {
+			start++
+		}
+		if start >= i || !hasTimeAndDistance(records[start]) {
+			continue
+		}
+		seconds := r.Time.Value.Sub(records[start].Time.Value).Seconds()
+		if seconds <= 0 || r.Distance.Value < records[start].Distance.Value {
+			continue
+		}
+		// cm/s -> mm/s
+		speed := NewSpeed(float32(float64(r.Distance.Value-records[start].Distance.Value) * 10 / seconds))
+		records[i].Speed = Ptr(speed)
+	}
+}
+
+// Calculates stats of all values of given records,
+// e.g. averages and min/max of speed, temperature, heartrate, cadence or power.
+// Totals (distance, duration, elevation) are calculated by records as well
+// (e.g. for GPX or TCX files), FIT files use totals reported by the device instead.
+func SummarizeRecords(records []RecordData) ActivityData {
+	speedStats := SpeedStats{}
+	var speedCount uint
+	var speedSum uint64
+
+	temperatureStats := TemperatureStats{}
+	var tempSum int64
+	var tempCount uint
+
+	gpsAccuracyStats := GpsAccuracyStats{}
+	var gpsSum, gpsCount uint
+
+	altitudeStats := AltitudeStats{}
+
+	heartrateStats := HeartrateStats{}
+	var heartrateSum, heartrateCount uint
+
+	cadenceStats := CadenceStats{}
+	var cadenceSum, cadenceCount, cadenceNonZeroCount uint
+
+	powerStats := PowerStats{}
+	var powerSum uint64
+	var powerCount uint
+
+	var totalDistance *Distance
+
+	for _, r := range records {
+		if r.Altitude != nil {
+			// initialize `max`/`min` on first valid `Altitude`
+			if altitudeStats.Min == nil || r.Altitude.Value < altitudeStats.Min.Value {
+				altitudeStats.Min = r.Altitude
+			}
+			if altitudeStats.Max == nil || r.Altitude.Value > altitudeStats.Max.Value {
+				altitudeStats.Max = r.Altitude
+			}
+		}
+
+		if r.Temperature != nil {
+			if temperatureStats.Min == nil || r.Temperature.Value < temperatureStats.Min.Value {
+				temperatureStats.Min = r.Temperature
+			}
+			if temperatureStats.Max == nil || r.Temperature.Value > temperatureStats.Max.Value {
+				temperatureStats.Max = r.Temperature
+			}
+			tempCount += 1
+			tempSum += int64(r.Temperature.Value)
+		}
+
+		if r.Distance != nil {
+			totalDistance = r.Distance
+		}
+
+		if r.Speed != nil {
+			if speedStats.Max == nil || speedStats.Max.Value < r.Speed.Value {
+				speedStats.Max = r.Speed
+			}
+			speedCount += 1
+			speedSum += uint64(r.Speed.Value)
+		}
+
+		if r.GpsAccuracy != nil {
+			if gpsAccuracyStats.Min == nil || r.GpsAccuracy.Value < gpsAccuracyStats.Min.Value {
+				gpsAccuracyStats.Min = r.GpsAccuracy
+			}
+			if gpsAccuracyStats.Max == nil || r.GpsAccuracy.Value > gpsAccuracyStats.Max.Value {
+				gpsAccuracyStats.Max = r.GpsAccuracy
+			}
+			gpsCount += 1
+			gpsSum += uint(r.GpsAccuracy.Value)
+		}
+
+		if r.Heartrate != nil {
+			if heartrateStats.Min == nil || r.Heartrate.Value < heartrateStats.Min.Value {
+				heartrateStats.Min = r.Heartrate
+			}
+			if heartrateStats.Max == nil || r.Heartrate.Value > heartrateStats.Max.Value {
+				heartrateStats.Max = r.Heartrate
+			}
+			heartrateCount += 1
+			heartrateSum += uint(r.Heartrate.Value)
+		}
+
+		if r.Cadence != nil {
+			if cadenceStats.Min == nil || r.Cadence.Value < cadenceStats.Min.Value {
+				cadenceStats.Min = r.Cadence
+			}
+			if cadenceStats.Max == nil || r.Cadence.Value > cadenceStats.Max.Value {
+				cadenceStats.Max = r.Cadence
+			}
+			cadenceCount += 1
+			if r.Cadence.Value > 0 {
+				cadenceNonZeroCount += 1
+			}
+			cadenceSum += uint(r.Cadence.Value)
+		}
+
+		if r.Power != nil {
+			if powerStats.Max == nil || r.Power.Value > powerStats.Max.Value {
+				powerStats.Max = r.Power
+			}
+			powerCount += 1
+			powerSum += uint64(r.Power.Value)
+		}
+	}
+
+	// Calculate `Speed` average
+	if speedCount > 0 {
+		speed := NewSpeed(float32(speedSum) / float32(speedCount))
+		speedStats.Avg = Ptr(speed)
+	}
+
+	// Calculate `Temperature` average
+	// Use `math.Round` for symmetric handling: truncation has sign-dependent bias
+	// (positive: 16.5°C → 16°C; negative: -0.5°C → 0°C truncates toward zero)
+	if tempCount > 0 {
+		temperature := NewTemperature(int8(math.Round(float64(tempSum) / float64(tempCount))))
+		temperatureStats.Avg = Ptr(temperature)
+	}
+
+	// Calculate `GpsAccuracy` average
+	if gpsCount > 0 {
+		gpsAccuracy := NewGpsAccuracy(uint8(float32(gpsSum) / float32(gpsCount)))
+		gpsAccuracyStats.Avg = Ptr(gpsAccuracy)
+	}
+
+	// Calculate `Heartrate` average
+	if heartrateCount > 0 {
+		heartrate := NewHeartrate(uint8(float32(heartrateSum) / float32(heartrateCount)))
+		heartrateStats.Avg = Ptr(heartrate)
+	}
+
+	// Calculate `Cadence` averages (with and without zero values)
+	if cadenceCount > 0 {
+		cadence := NewCadence(uint8(math.Round(float64(cadenceSum) / float64(cadenceCount))))
+		cadenceStats.AvgWithZeros = Ptr(cadence)
+	}
+	if cadenceNonZeroCount > 0 {
+		cadence := NewCadence(uint8(math.Round(float64(cadenceSum) / float64(cadenceNonZeroCount))))
+		cadenceStats.Avg = Ptr(cadence)
+	}
+
+	// Calculate `Power` average
+	if powerCount > 0 {
+		power := NewPower(uint16(math.Round(float64(powerSum) / float64(powerCount))))
+		powerStats.Avg = Ptr(power)
+	}
+	powerStats.Normalized = NormalizedPower(records)
+
+	return ActivityData{
+		Duration:      DurationOfRecords(records),
+		TotalDistance: totalDistance,
+		Temperature:   temperatureStats,
+		Speed:         speedStats,
+		Elevation:     ElevationOfRecords(records, ElevationThreshold),
+		Records:       records,
+		GpsAccuracy:   gpsAccuracyStats,
+		Altitude:      altitudeStats,
+		Heartrate:     heartrateStats,
+		Power:         powerStats,
+		Cadence:       cadenceStats,
+		Sport:         NewSport(SportGeneric, ""),
+	}
+}
+
+// Rolling window used to smooth `Power` values before calculating normalized power
+const normalizedPowerWindow = 30 * time.Second
+
+// `NormalizedPower` calculates normalized power (NP) of given records:
+// 1. rolling average of `Power` over `normalizedPowerWindow`
+// 2. raise each average to the 4th power
+// 3. take the 4th root of the mean of these values
+// Averages are counted once the first window is filled.
+// Returns `nil` if there are not enough `Power` values to fill a window.
+func NormalizedPower(records []RecordData) *Power {
+	type sample struct {
+		time  time.Time
+		value float64
+	}
+
+	var window []sample
+	var windowSum, sum4 float64
+	var count uint
+	var first *time.Time
+
+	for _, r := range records {
+		if r.Power == nil || r.Time == nil {
+			continue
+		}
+		current := sample{time: r.Time.Value, value: float64(r.Power.Value)}
+		if first == nil {
+			first = &current.time
+		}
+
+		window = append(window, current)
+		windowSum += current.value
+		// drop samples out of the window
+		for current.time.Sub(window[0].time) >= normalizedPowerWindow {
+			windowSum -= window[0].value
+			window = window[1:]
+		}
+
+		// ignore averages until the first window is filled
+		if current.time.Sub(*first) < normalizedPowerWindow-time.Second {
+			continue
+		}
+
+		avg := windowSum / float64(len(window))
+		sum4 += math.Pow(avg, 4)
+		count += 1
+	}
+
+	if count == 0 {
+		return nil
+	}
+
+	power := NewPower(uint16(math.Round(math.Pow(sum4/float64(count), 0.25))))
+	return Ptr(power)
+}
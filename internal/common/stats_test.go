@@ -0,0 +1,202 @@
+package common
+
+import (
+	"testing"
+	"time"
+)
+
+func powerRecords(start time.Time, values []uint16) []RecordData {
+	records := make([]RecordData, len(values))
+	for i, v := range values {
+		t := NewTime(start.Add(time.Duration(i) * time.Second))
+		records[i] = RecordData{
+			Time:  Ptr(t),
+			Power: Ptr(NewPower(v)),
+		}
+	}
+	return records
+}
+
+func TestNormalizedPower(t *testing.T) {
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	constant := make([]uint16, 120)
+	for i := range constant {
+		constant[i] = 200
+	}
+
+	// 60s at 100W followed by 60s at 300W
+	intervals := make([]uint16, 120)
+	for i := range intervals {
+		if i < 60 {
+			intervals[i] = 100
+		} else {
+			intervals[i] = 300
+		}
+	}
+
+	tests := []struct {
+		name     string
+		records  []RecordData
+		expected *uint16
+	}{
+		{
+			name:     "no records",
+			records:  []RecordData{},
+			expected: nil,
+		},
+		{
+			name:     "less than 30s of data",
+			records:  powerRecords(start, constant[:10]),
+			expected: nil,
+		},
+		{
+			name:     "constant power",
+			records:  powerRecords(start, constant),
+			expected: Ptr(uint16(200)),
+		},
+		{
+			name:     "intervals are weighted higher than average",
+			records:  powerRecords(start, intervals),
+			expected: Ptr(uint16(244)),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := NormalizedPower(tt.records)
+
+			if tt.expected == nil {
+				if result != nil {
+					t.Errorf("Expected: nil, Got: %s", result.Format())
+				}
+				return
+			}
+			if result == nil {
+				t.Fatalf("Expected: %dW, Got: nil", *tt.expected)
+			}
+			if result.Value != *tt.expected {
+				t.Errorf("Expected: %dW, Got: %s", *tt.expected, result.Format())
+			}
+		})
+	}
+}
+
+func TestElevationOfRecords(t *testing.T) {
+	altitudes := func(values ...float64) []RecordData {
+		records := make([]RecordData, len(values))
+		for i, v := range values {
+			records[i] = RecordData{Altitude: Ptr(NewAltitude(v))}
+		}
+		return records
+	}
+
+	tests := []struct {
+		name     string
+		records  []RecordData
+		ascents  uint16
+		descents uint16
+	}{
+		{
+			name:     "steady climb",
+			records:  altitudes(100, 104, 108, 112),
+			ascents:  12,
+			descents: 0,
+		},
+		{
+			name:     "noise below threshold is ignored",
+			records:  altitudes(100, 101, 99, 101, 100, 102),
+			ascents:  0,
+			descents: 0,
+		},
+		{
+			name:     "up and down",
+			records:  altitudes(100, 110, 111, 109, 95, 96),
+			ascents:  10,
+			descents: 15,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ElevationOfRecords(tt.records, ElevationThreshold)
+			if result.Ascents == nil || result.Ascents.Value != tt.ascents {
+				t.Errorf("Expected ascents: %d, Got: %v", tt.ascents, result.Ascents)
+			}
+			if result.Descents == nil || result.Descents.Value != tt.descents {
+				t.Errorf("Expected descents: %d, Got: %v", tt.descents, result.Descents)
+			}
+		})
+	}
+
+	if result := ElevationOfRecords([]RecordData{{}}, ElevationThreshold); result.Ascents != nil {
+		t.Errorf("Expected: nil, Got: %v", result.Ascents)
+	}
+}
+
+func altitudeRecords(values []float64) []RecordData {
+	records := make([]RecordData, len(values))
+	for i, v := range values {
+		records[i] = RecordData{Altitude: Ptr(NewAltitude(v))}
+	}
+	return records
+}
+
+func TestSmoothAltitudes(t *testing.T) {
+	records := altitudeRecords([]float64{100, 103, 100, 103, 100})
+	// record without altitude is kept
+	records = append(records[:2], append([]RecordData{{}}, records[2:]...)...)
+
+	smoothed := SmoothAltitudes(records, 3)
+	expected := []*float64{Ptr(101.5), Ptr(101.0), nil, Ptr(102.0), Ptr(101.0), Ptr(101.5)}
+	for i, e := range expected {
+		got := smoothed[i].Altitude
+		if (e == nil) != (got == nil) || (e != nil && got.Value != *e) {
+			t.Errorf("record %d: Expected: %v, Got: %v", i, e, got)
+		}
+	}
+	// original records are untouched
+	if records[1].Altitude.Value != 103 {
+		t.Errorf("Expected original altitude: 103, Got: %v", records[1].Altitude.Value)
+	}
+}
+
+func TestRecomputeTotals(t *testing.T) {
+	// noise of 4m on a flat route, followed by a climb of 24m
+	var altitudes []float64
+	for i := range 20 {
+		altitudes = append(altitudes, 100+float64(i%2)*4)
+	}
+	for i := range 6 {
+		altitudes = append(altitudes, 108+float64(i)*4)
+	}
+	records := altitudeRecords(altitudes)
+	for i := range records {
+		records[i].Position = Ptr(NewPosition(52.52+float64(i)*0.001, 13.4))
+	}
+
+	tests := []struct {
+		name    string
+		opts    RecomputeOptions
+		ascents uint16
+	}{
+		// each +4m of noise is counted
+		{"no smoothing", RecomputeOptions{ElevationThreshold: 3}, 64},
+		// noise is ignored, climb is flattened a bit at its end
+		{"smoothing", RecomputeOptions{ElevationThreshold: 3, SmoothingWindow: 5}, 23},
+	}
+	for _, test := range tests {
+		totals := RecomputeTotals(records, test.opts)
+		if totals.Elevation.Ascents == nil || totals.Elevation.Ascents.Value != test.ascents {
+			t.Errorf("%s: Expected ascents: %d, Got: %v", test.name, test.ascents, totals.Elevation.Ascents)
+		}
+		// 25 * ~111.2m
+		if totals.Distance == nil || totals.Distance.Value/100 != 2779 {
+			t.Errorf("%s: Expected distance: 2779m, Got: %v", test.name, totals.Distance)
+		}
+	}
+
+	if totals := RecomputeTotals(altitudeRecords([]float64{100}), DefaultRecomputeOptions()); totals.Distance != nil {
+		t.Errorf("Expected no distance without positions, Got: %v", totals.Distance)
+	}
+}
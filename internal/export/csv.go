@@ -0,0 +1,147 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/sectore/fit-activities-tui/internal/asyncdata"
+	"github.com/sectore/fit-activities-tui/internal/common"
+)
+
+// Columns of records. Names include units (snake case) to be used as they are
+// by data frames (e.g. pandas, R) or to be converted into columnar formats (e.g. Parquet).
+// Note: Keep the schema stable, add new columns at the end only.
+var RecordColumns = []string{
+	"time",
+	"elapsed_s",
+	"lat_deg",
+	"long_deg",
+	"distance_m",
+	"speed_m_s",
+	"altitude_m",
+	"heartrate_bpm",
+	"cadence_rpm",
+	"power_w",
+	"temperature_c",
+	"gps_accuracy_m",
+}
+
+// Columns of summaries of activities (see `RecordColumns`)
+var SummaryColumns = []string{
+	"file",
+	"sport",
+	"sub_sport",
+	"start_time",
+	"finish_time",
+	"distance_m",
+	"duration_s",
+	"active_s",
+	"pause_s",
+	"ascent_m",
+	"descent_m",
+	"altitude_min_m",
+	"altitude_max_m",
+	"speed_avg_m_s",
+	"speed_max_m_s",
+	"heartrate_avg_bpm",
+	"heartrate_min_bpm",
+	"heartrate_max_bpm",
+	"cadence_avg_rpm",
+	"cadence_max_rpm",
+	"power_avg_w",
+	"power_max_w",
+	"power_np_w",
+	"temperature_avg_c",
+	"temperature_min_c",
+	"temperature_max_c",
+	"records",
+	"laps",
+	"sessions",
+}
+
+// Helpers to format optional values. "No data" is an empty string.
+
+func csvFloat(v *float64) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*v, 'f', -1, 64)
+}
+
+func csvInt[T int8 | uint8 | uint16](v *T) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.Itoa(int(*v))
+}
+
+func csvTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// Writes all records of an activity as CSV (see `RecordColumns`)
+func RecordsCSV(w io.Writer, data common.ActivityData) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(RecordColumns); err != nil {
+		return err
+	}
+	// time of the first record with a time
+	var start *common.Time
+	for _, r := range data.Records {
+		if r.Time != nil {
+			start = r.Time
+			break
+		}
+	}
+	for _, r := range data.Records {
+		var elapsed *float64
+		if start != nil && r.Time != nil {
+			elapsed = common.Ptr(r.Time.Value.Sub(start.Value).Seconds())
+		}
+		row := []string{
+			csvTime(optional(r.Time, timeValue)),
+			csvFloat(elapsed),
+			csvFloat(optional(r.Position, positionLat)),
+			csvFloat(optional(r.Position, positionLong)),
+			csvFloat(optional(r.Distance, distanceMeters)),
+			csvFloat(optional(r.Speed, speedMetersPerSecond)),
+			csvFloat(optional(r.Altitude, altitudeMeters)),
+			csvInt(optional(r.Heartrate, heartrateBpm)),
+			csvInt(optional(r.Cadence, cadenceRpm)),
+			csvInt(optional(r.Power, powerWatts)),
+			csvInt(optional(r.Temperature, temperatureCelsius)),
+			csvInt(optional(r.GpsAccuracy, gpsAccuracyMeters)),
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// Writes summaries of activities as CSV, one row each (see `SummaryColumns`).
+// Activities, which are not parsed successfully, are skipped.
+func SummaryCSV(w io.Writer, acts []*common.Activity) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(SummaryColumns); err != nil {
+		return err
+	}
+	for _, act := range acts {
+		data, ok := asyncdata.Success(act.Data)
+		if !ok {
+			continue
+		}
+		row := NewSummary(act.Path, *data).CSVRecord()
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,109 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"testing"
+
+	"github.com/sectore/fit-activities-tui/internal/asyncdata"
+	"github.com/sectore/fit-activities-tui/internal/common"
+)
+
+var errTest = errors.New("test")
+
+func readCSV(t *testing.T, buf *bytes.Buffer) [][]string {
+	t.Helper()
+	rows, err := csv.NewReader(buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rows
+}
+
+// Value of a row by column name
+func column(t *testing.T, header, row []string, name string) string {
+	t.Helper()
+	for i, h := range header {
+		if h == name {
+			return row[i]
+		}
+	}
+	t.Fatalf("Column %s not found", name)
+	return ""
+}
+
+func TestRecordsCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := RecordsCSV(&buf, testActivity()); err != nil {
+		t.Fatal(err)
+	}
+	rows := readCSV(t, &buf)
+	// header + 4 records
+	if len(rows) != 5 {
+		t.Fatalf("Expected 5 rows, Got: %d", len(rows))
+	}
+	header := rows[0]
+
+	tests := []struct {
+		row      int
+		column   string
+		expected string
+	}{
+		// first record has no data but heartrate
+		{1, "time", ""},
+		{1, "lat_deg", ""},
+		{1, "heartrate_bpm", "100"},
+		{2, "time", "2025-06-01T08:00:00Z"},
+		{2, "elapsed_s", "0"},
+		{3, "elapsed_s", "1"},
+		{2, "lat_deg", "52.52"},
+		{2, "altitude_m", "40"},
+		{3, "cadence_rpm", "81"},
+		{3, "temperature_c", "19"},
+		{3, "power_w", ""},
+	}
+	for _, tt := range tests {
+		if result := column(t, header, rows[tt.row], tt.column); result != tt.expected {
+			t.Errorf("Row %d %s: Expected: %q, Got: %q", tt.row, tt.column, tt.expected, result)
+		}
+	}
+}
+
+func TestSummaryCSV(t *testing.T) {
+	acts := []*common.Activity{
+		{Path: "ride.fit", Data: asyncdata.NewSuccess[error](testActivity())},
+		{Path: "broken.fit", Data: asyncdata.NewFailure[error, common.ActivityData](errTest)},
+	}
+	var buf bytes.Buffer
+	if err := SummaryCSV(&buf, acts); err != nil {
+		t.Fatal(err)
+	}
+	rows := readCSV(t, &buf)
+	// header + 1 successful activity
+	if len(rows) != 2 {
+		t.Fatalf("Expected 2 rows, Got: %d", len(rows))
+	}
+	header := rows[0]
+	if len(header) != len(SummaryColumns) || len(rows[1]) != len(SummaryColumns) {
+		t.Errorf("Expected %d columns, Got: %d", len(SummaryColumns), len(rows[1]))
+	}
+
+	tests := []struct {
+		column   string
+		expected string
+	}{
+		{"file", "ride.fit"},
+		{"sport", "cycling"},
+		{"sub_sport", ""},
+		{"heartrate_max_bpm", "122"},
+		{"power_avg_w", ""},
+		{"records", "4"},
+		{"laps", "0"},
+	}
+	for _, tt := range tests {
+		if result := column(t, header, rows[1], tt.column); result != tt.expected {
+			t.Errorf("%s: Expected: %q, Got: %q", tt.column, tt.expected, result)
+		}
+	}
+}
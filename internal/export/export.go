@@ -0,0 +1,97 @@
+// Package export writes activities into files of other formats (e.g. GPX),
+// to be used by other tools.
+package export
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sectore/fit-activities-tui/internal/common"
+)
+
+// Supported formats
+const (
+	FormatGPX = "gpx"
+	// records only, see `SummaryCSV` for summaries
+	FormatCSV = "csv"
+)
+
+var Formats = []string{FormatGPX, FormatCSV}
+
+// Writes an activity in given format.
+// Records inside of privacy zones are dropped.
+func Write(w io.Writer, format string, name string, data common.ActivityData, zones common.PrivacyZones) error {
+	data.Records = zones.FilterRecords(data.Records)
+	switch format {
+	case FormatGPX:
+		return GPX(w, name, data)
+	case FormatCSV:
+		return RecordsCSV(w, data)
+	default:
+		return fmt.Errorf("unsupported format %q (supported: %s)", format, strings.Join(Formats, ", "))
+	}
+}
+
+// Name of an activity by its path, e.g. `ride` of `/data/ride.fit.gz`
+func Name(path string) string {
+	name := filepath.Base(path)
+	name = strings.TrimSuffix(name, ".gz")
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
+
+// Creates a new file named by given name and extension in given directory.
+// Existing files are never overwritten, a number is added to the name instead (e.g. `ride-1.gpx`).
+func CreateFile(dir, name, ext string) (*os.File, error) {
+	for i := 0; ; i++ {
+		fileName := name + "." + ext
+		if i > 0 {
+			fileName = fmt.Sprintf("%s-%d.%s", name, i, ext)
+		}
+		f, err := os.OpenFile(filepath.Join(dir, fileName), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if errors.Is(err, os.ErrExist) {
+			continue
+		}
+		return f, err
+	}
+}
+
+// Exports summaries of activities (CSV) into a new file of given directory.
+// Returns path of the new file.
+func SummaryToFile(dir string, acts []*common.Activity) (string, error) {
+	f, err := CreateFile(dir, "activities", FormatCSV)
+	if err != nil {
+		return "", fmt.Errorf("failed to export summary: %v", err)
+	}
+	if err := SummaryCSV(f, acts); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return "", fmt.Errorf("failed to export summary: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		return "", fmt.Errorf("failed to export summary: %v", err)
+	}
+	return f.Name(), nil
+}
+
+// Exports an activity into a new file of given directory (see `Write`).
+// Returns path of the new file.
+func ToFile(dir, format, path string, data common.ActivityData, zones common.PrivacyZones) (string, error) {
+	name := Name(path)
+	f, err := CreateFile(dir, name, format)
+	if err != nil {
+		return "", fmt.Errorf("failed to export %s: %v", name, err)
+	}
+	if err := Write(f, format, name, data, zones); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return "", fmt.Errorf("failed to export %s: %v", name, err)
+	}
+	if err := f.Close(); err != nil {
+		return "", fmt.Errorf("failed to export %s: %v", name, err)
+	}
+	return f.Name(), nil
+}
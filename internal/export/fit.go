@@ -0,0 +1,30 @@
+package export
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/muktihari/fit/profile/filedef"
+	"github.com/sectore/fit-activities-tui/internal/fit"
+)
+
+// Extension of FIT files
+const extFIT = "fit"
+
+// Writes an activity (e.g. a cropped one) into a new FIT file of given directory.
+// Returns path of the new file.
+func ActivityToFile(dir, name string, act *filedef.Activity) (string, error) {
+	f, err := CreateFile(dir, name, extFIT)
+	if err != nil {
+		return "", fmt.Errorf("failed to write %s: %v", name, err)
+	}
+	if err := fit.WriteActivity(f, act); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return "", fmt.Errorf("failed to write %s: %v", name, err)
+	}
+	if err := f.Close(); err != nil {
+		return "", fmt.Errorf("failed to write %s: %v", name, err)
+	}
+	return f.Name(), nil
+}
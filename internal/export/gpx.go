@@ -0,0 +1,137 @@
+package export
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/sectore/fit-activities-tui/internal/common"
+)
+
+const (
+	gpxNamespace         = "http://www.topografix.com/GPX/1/1"
+	gpxSchemaLocation    = "http://www.topografix.com/GPX/1/1 http://www.topografix.com/GPX/1/1/gpx.xsd"
+	gpxtpxNamespace      = "http://www.garmin.com/xmlschemas/TrackPointExtension/v1"
+	gpxtpxSchemaLocation = "http://www.garmin.com/xmlschemas/TrackPointExtension/v1 http://www.garmin.com/xmlschemas/TrackPointExtensionv1.xsd"
+	xsiNamespace         = "http://www.w3.org/2001/XMLSchema-instance"
+	creator              = "fit-activities-tui"
+)
+
+// GPX 1.1, see https://www.topografix.com/GPX/1/1/
+// Note: Names with prefixes (e.g. `gpxtpx:hr`) are written as they are.
+type gpx struct {
+	XMLName        xml.Name    `xml:"gpx"`
+	Version        string      `xml:"version,attr"`
+	Creator        string      `xml:"creator,attr"`
+	Xmlns          string      `xml:"xmlns,attr"`
+	XmlnsGpxtpx    string      `xml:"xmlns:gpxtpx,attr"`
+	XmlnsXsi       string      `xml:"xmlns:xsi,attr"`
+	SchemaLocation string      `xml:"xsi:schemaLocation,attr"`
+	Metadata       gpxMetadata `xml:"metadata"`
+	Track          gpxTrack    `xml:"trk"`
+}
+
+type gpxMetadata struct {
+	Name string `xml:"name,omitempty"`
+	Time string `xml:"time,omitempty"`
+}
+
+type gpxTrack struct {
+	Name    string     `xml:"name,omitempty"`
+	Type    string     `xml:"type,omitempty"`
+	Segment []gpxTrkPt `xml:"trkseg>trkpt"`
+}
+
+type gpxTrkPt struct {
+	Lat        string         `xml:"lat,attr"`
+	Lon        string         `xml:"lon,attr"`
+	Ele        string         `xml:"ele,omitempty"`
+	Time       string         `xml:"time,omitempty"`
+	Extensions *gpxExtensions `xml:"extensions,omitempty"`
+}
+
+type gpxExtensions struct {
+	TrackPointExtension gpxTrackPointExtension `xml:"gpxtpx:TrackPointExtension"`
+}
+
+// Order of elements is defined by the schema of `TrackPointExtension`
+type gpxTrackPointExtension struct {
+	Temperature string `xml:"gpxtpx:atemp,omitempty"`
+	Heartrate   string `xml:"gpxtpx:hr,omitempty"`
+	Cadence     string `xml:"gpxtpx:cad,omitempty"`
+}
+
+func formatGPXTime(t common.Time) string {
+	return t.Value.UTC().Format(time.RFC3339)
+}
+
+// Writes all records with a `Position` as GPX 1.1 track.
+// Heartrate, cadence and temperature are written as Garmin `TrackPointExtension`.
+func GPX(w io.Writer, name string, data common.ActivityData) error {
+	doc := gpx{
+		Version:        "1.1",
+		Creator:        creator,
+		Xmlns:          gpxNamespace,
+		XmlnsGpxtpx:    gpxtpxNamespace,
+		XmlnsXsi:       xsiNamespace,
+		SchemaLocation: gpxSchemaLocation + " " + gpxtpxSchemaLocation,
+		Metadata:       gpxMetadata{Name: name},
+		Track: gpxTrack{
+			Name: name,
+			Type: data.Sport.Name,
+		},
+	}
+	if start := data.StartTime(); start != nil {
+		doc.Metadata.Time = formatGPXTime(*start)
+	}
+
+	for _, r := range data.Records {
+		// track points without position are invalid
+		if r.Position == nil {
+			continue
+		}
+		pt := gpxTrkPt{
+			Lat: fmt.Sprintf("%.7f", r.Position.Lat),
+			Lon: fmt.Sprintf("%.7f", r.Position.Long),
+		}
+		if r.Altitude != nil {
+			pt.Ele = fmt.Sprintf("%.1f", r.Altitude.Value)
+		}
+		if r.Time != nil {
+			pt.Time = formatGPXTime(*r.Time)
+		}
+		ext := gpxTrackPointExtension{}
+		if r.Temperature != nil {
+			ext.Temperature = fmt.Sprintf("%d", r.Temperature.Value)
+		}
+		if r.Heartrate != nil {
+			ext.Heartrate = fmt.Sprintf("%d", r.Heartrate.Value)
+		}
+		if r.Cadence != nil {
+			ext.Cadence = fmt.Sprintf("%d", r.Cadence.Value)
+		}
+		if ext != (gpxTrackPointExtension{}) {
+			pt.Extensions = &gpxExtensions{TrackPointExtension: ext}
+		}
+		doc.Track.Segment = append(doc.Track.Segment, pt)
+	}
+
+	if len(doc.Track.Segment) == 0 {
+		return fmt.Errorf("no positions found")
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,124 @@
+package export
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sectore/fit-activities-tui/internal/common"
+	"github.com/sectore/fit-activities-tui/internal/fit"
+)
+
+func testActivity() common.ActivityData {
+	start := time.Date(2025, 6, 1, 8, 0, 0, 0, time.UTC)
+	var records []common.RecordData
+	for i := range 3 {
+		t := common.NewTime(start.Add(time.Duration(i) * time.Second))
+		records = append(records, common.RecordData{
+			Time:        common.Ptr(t),
+			Position:    common.Ptr(common.NewPosition(52.52+float64(i)*0.0001, 13.4)),
+			Altitude:    common.Ptr(common.NewAltitude(40 + float64(i))),
+			Heartrate:   common.Ptr(common.NewHeartrate(uint8(120 + i))),
+			Cadence:     common.Ptr(common.NewCadence(uint8(80 + i))),
+			Temperature: common.Ptr(common.NewTemperature(int8(18 + i))),
+		})
+	}
+	// record without position (e.g. no GPS fix yet)
+	records = append([]common.RecordData{{Heartrate: common.Ptr(common.NewHeartrate(100))}}, records...)
+	data := common.SummarizeRecords(records)
+	data.Sport = common.NewSport(common.SportCycling, "")
+	return data
+}
+
+func TestGPX(t *testing.T) {
+	var buf bytes.Buffer
+	if err := GPX(&buf, "ride", testActivity()); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, expected := range []string{
+		`<gpx version="1.1" creator="fit-activities-tui" xmlns="http://www.topografix.com/GPX/1/1"`,
+		`<trkpt lat="52.5200000" lon="13.4000000">`,
+		`<ele>40.0</ele>`,
+		`<time>2025-06-01T08:00:00Z</time>`,
+		`<gpxtpx:atemp>18</gpxtpx:atemp>`,
+		`<gpxtpx:hr>120</gpxtpx:hr>`,
+		`<gpxtpx:cad>80</gpxtpx:cad>`,
+		`<type>cycling</type>`,
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("Expected %s in:\n%s", expected, out)
+		}
+	}
+
+	// parse exported file again
+	path := filepath.Join(t.TempDir(), "ride.gpx")
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	data, err := fit.ParseFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.NoRecords() != 3 {
+		t.Errorf("Expected 3 records, Got: %d", data.NoRecords())
+	}
+	if data.Heartrate.Max == nil || data.Heartrate.Max.Value != 122 {
+		t.Errorf("Expected max. heartrate: 122, Got: %v", data.Heartrate.Max)
+	}
+	if data.Sport.Name != common.SportCycling {
+		t.Errorf("Expected sport: cycling, Got: %s", data.Sport.Name)
+	}
+
+	t.Run("no positions", func(t *testing.T) {
+		if err := GPX(&bytes.Buffer{}, "indoor", common.ActivityData{}); err == nil {
+			t.Error("Expected error")
+		}
+	})
+}
+
+func TestToFile(t *testing.T) {
+	dir := t.TempDir()
+	expected := []string{"ride.gpx", "ride-1.gpx"}
+	for _, name := range expected {
+		path, err := ToFile(dir, FormatGPX, "/data/ride.fit.gz", testActivity(), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if filepath.Base(path) != name {
+			t.Errorf("Expected: %s, Got: %s", name, filepath.Base(path))
+		}
+	}
+	if _, err := ToFile(dir, "kml", "ride.fit", testActivity(), nil); err == nil {
+		t.Error("Expected error for unsupported format")
+	}
+	// failed exports don't leave any files
+	if entries, _ := os.ReadDir(dir); len(entries) != 2 {
+		t.Errorf("Expected 2 files, Got: %d", len(entries))
+	}
+}
+
+func TestWritePrivacyZones(t *testing.T) {
+	// first record with a position only (other records are 11m+ away)
+	zones := common.PrivacyZones{{Center: common.NewPosition(52.52, 13.4), Radius: 5}}
+
+	var gpx bytes.Buffer
+	if err := Write(&gpx, FormatGPX, "ride", testActivity(), zones); err != nil {
+		t.Fatal(err)
+	}
+	if out := gpx.String(); strings.Contains(out, `lat="52.5200000"`) || !strings.Contains(out, `lat="52.5201000"`) {
+		t.Errorf("Expected points outside of privacy zones only:\n%s", out)
+	}
+
+	var csv bytes.Buffer
+	if err := Write(&csv, FormatCSV, "ride", testActivity(), zones); err != nil {
+		t.Fatal(err)
+	}
+	// header + 3 records (incl. record without position)
+	if rows := readCSV(t, &csv); len(rows) != 4 {
+		t.Errorf("Expected 4 rows, Got: %d", len(rows))
+	}
+}
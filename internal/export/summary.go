@@ -0,0 +1,158 @@
+package export
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/sectore/fit-activities-tui/internal/common"
+)
+
+// Summary of an activity using plain units (see `SummaryColumns`).
+// Optional values are `nil` if there is no data.
+type Summary struct {
+	File            string     `json:"file"`
+	Sport           string     `json:"sport"`
+	SubSport        string     `json:"sub_sport"`
+	StartTime       *time.Time `json:"start_time"`
+	FinishTime      *time.Time `json:"finish_time"`
+	DistanceM       *float64   `json:"distance_m"`
+	DurationS       *float64   `json:"duration_s"`
+	ActiveS         *float64   `json:"active_s"`
+	PauseS          *float64   `json:"pause_s"`
+	AscentM         *uint16    `json:"ascent_m"`
+	DescentM        *uint16    `json:"descent_m"`
+	AltitudeMinM    *float64   `json:"altitude_min_m"`
+	AltitudeMaxM    *float64   `json:"altitude_max_m"`
+	SpeedAvgMS      *float64   `json:"speed_avg_m_s"`
+	SpeedMaxMS      *float64   `json:"speed_max_m_s"`
+	HeartrateAvgBpm *uint8     `json:"heartrate_avg_bpm"`
+	HeartrateMinBpm *uint8     `json:"heartrate_min_bpm"`
+	HeartrateMaxBpm *uint8     `json:"heartrate_max_bpm"`
+	CadenceAvgRpm   *uint8     `json:"cadence_avg_rpm"`
+	CadenceMaxRpm   *uint8     `json:"cadence_max_rpm"`
+	PowerAvgW       *uint16    `json:"power_avg_w"`
+	PowerMaxW       *uint16    `json:"power_max_w"`
+	PowerNpW        *uint16    `json:"power_np_w"`
+	TemperatureAvgC *int8      `json:"temperature_avg_c"`
+	TemperatureMinC *int8      `json:"temperature_min_c"`
+	TemperatureMaxC *int8      `json:"temperature_max_c"`
+	Records         int        `json:"records"`
+	Laps            int        `json:"laps"`
+	Sessions        uint32     `json:"sessions"`
+}
+
+// Totals recalculated by records (see `common.RecomputeTotals`) using plain units
+type RecomputedSummary struct {
+	DistanceM *float64 `json:"distance_m"`
+	AscentM   *uint16  `json:"ascent_m"`
+	DescentM  *uint16  `json:"descent_m"`
+}
+
+func NewRecomputedSummary(totals common.RecomputedTotals) RecomputedSummary {
+	return RecomputedSummary{
+		DistanceM: optional(totals.Distance, distanceMeters),
+		AscentM:   optional(totals.Elevation.Ascents, elevationMeters),
+		DescentM:  optional(totals.Elevation.Descents, elevationMeters),
+	}
+}
+
+// Maps an optional value (e.g. `*common.Heartrate`) into an optional plain value
+func optional[A any, B any](value *A, f func(A) B) *B {
+	if value == nil {
+		return nil
+	}
+	return common.Ptr(f(*value))
+}
+
+func timeValue(t common.Time) time.Time { return t.Value.UTC() }
+
+// cm -> m
+func distanceMeters(d common.Distance) float64 { return float64(d.Value) / 100 }
+
+// ms -> s
+func durationSeconds(d common.Duration) float64 { return float64(d.Value) / 1000 }
+
+// mm/s -> m/s (`Speed` is a float32, which is rounded to its precision)
+func speedMetersPerSecond(s common.Speed) float64 {
+	v, _ := strconv.ParseFloat(strconv.FormatFloat(float64(s.Value/1000), 'f', -1, 32), 64)
+	return v
+}
+
+func altitudeMeters(a common.Altitude) float64     { return a.Value }
+func elevationMeters(e common.Elevation) uint16    { return e.Value }
+func heartrateBpm(h common.Heartrate) uint8        { return h.Value }
+func cadenceRpm(c common.Cadence) uint8            { return c.Value }
+func powerWatts(p common.Power) uint16             { return p.Value }
+func temperatureCelsius(t common.Temperature) int8 { return t.Value }
+func gpsAccuracyMeters(g common.GpsAccuracy) uint8 { return g.Value }
+func positionLat(p common.Position) float64        { return p.Lat }
+func positionLong(p common.Position) float64       { return p.Long }
+
+func NewSummary(path string, data common.ActivityData) Summary {
+	return Summary{
+		File:            path,
+		Sport:           data.Sport.Name,
+		SubSport:        data.Sport.SubName,
+		StartTime:       optional(data.StartTime(), timeValue),
+		FinishTime:      optional(data.FinishTime(), timeValue),
+		DistanceM:       optional(data.TotalDistance, distanceMeters),
+		DurationS:       optional(data.Duration.Total, durationSeconds),
+		ActiveS:         optional(data.Duration.Active, durationSeconds),
+		PauseS:          optional(data.Duration.Pause, durationSeconds),
+		AscentM:         optional(data.Elevation.Ascents, elevationMeters),
+		DescentM:        optional(data.Elevation.Descents, elevationMeters),
+		AltitudeMinM:    optional(data.Altitude.Min, altitudeMeters),
+		AltitudeMaxM:    optional(data.Altitude.Max, altitudeMeters),
+		SpeedAvgMS:      optional(data.Speed.Avg, speedMetersPerSecond),
+		SpeedMaxMS:      optional(data.Speed.Max, speedMetersPerSecond),
+		HeartrateAvgBpm: optional(data.Heartrate.Avg, heartrateBpm),
+		HeartrateMinBpm: optional(data.Heartrate.Min, heartrateBpm),
+		HeartrateMaxBpm: optional(data.Heartrate.Max, heartrateBpm),
+		CadenceAvgRpm:   optional(data.Cadence.Avg, cadenceRpm),
+		CadenceMaxRpm:   optional(data.Cadence.Max, cadenceRpm),
+		PowerAvgW:       optional(data.Power.Avg, powerWatts),
+		PowerMaxW:       optional(data.Power.Max, powerWatts),
+		PowerNpW:        optional(data.Power.Normalized, powerWatts),
+		TemperatureAvgC: optional(data.Temperature.Avg, temperatureCelsius),
+		TemperatureMinC: optional(data.Temperature.Min, temperatureCelsius),
+		TemperatureMaxC: optional(data.Temperature.Max, temperatureCelsius),
+		Records:         data.NoRecords(),
+		Laps:            len(data.Laps),
+		Sessions:        data.NoSessions,
+	}
+}
+
+// Values of all `SummaryColumns`
+func (s Summary) CSVRecord() []string {
+	return []string{
+		s.File,
+		s.Sport,
+		s.SubSport,
+		csvTime(s.StartTime),
+		csvTime(s.FinishTime),
+		csvFloat(s.DistanceM),
+		csvFloat(s.DurationS),
+		csvFloat(s.ActiveS),
+		csvFloat(s.PauseS),
+		csvInt(s.AscentM),
+		csvInt(s.DescentM),
+		csvFloat(s.AltitudeMinM),
+		csvFloat(s.AltitudeMaxM),
+		csvFloat(s.SpeedAvgMS),
+		csvFloat(s.SpeedMaxMS),
+		csvInt(s.HeartrateAvgBpm),
+		csvInt(s.HeartrateMinBpm),
+		csvInt(s.HeartrateMaxBpm),
+		csvInt(s.CadenceAvgRpm),
+		csvInt(s.CadenceMaxRpm),
+		csvInt(s.PowerAvgW),
+		csvInt(s.PowerMaxW),
+		csvInt(s.PowerNpW),
+		csvInt(s.TemperatureAvgC),
+		csvInt(s.TemperatureMinC),
+		csvInt(s.TemperatureMaxC),
+		strconv.Itoa(s.Records),
+		strconv.Itoa(s.Laps),
+		strconv.Itoa(int(s.Sessions)),
+	}
+}
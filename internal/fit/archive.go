@@ -0,0 +1,200 @@
+package fit
+
+import (
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Activity files can be compressed (e.g. `.fit.gz` of Strava exports)
+// or archived (e.g. `.zip` of Garmin or Strava bulk exports).
+// Files of an archive are referenced by virtual paths,
+// which is the path of the archive joined with the name of its entry,
+// e.g. `/exports/strava.zip/activities/123.fit.gz`.
+// Archives are never extracted to disk.
+
+const (
+	fitExt = ".fit"
+	gpxExt = ".gpx"
+	tcxExt = ".tcx"
+	gzExt  = ".gz"
+	zipExt = ".zip"
+)
+
+// Extension of a (compressed) activity file, e.g. `.fit` of `ride.fit.gz`
+func fileExt(name string) string {
+	return filepath.Ext(strings.TrimSuffix(name, gzExt))
+}
+
+// Checks the name of a (compressed) activity file (FIT, GPX or TCX)
+func isFitName(name string) bool {
+	switch fileExt(name) {
+	case fitExt, gpxExt, tcxExt:
+		return true
+	default:
+		return false
+	}
+}
+
+func isZipName(name string) bool {
+	return strings.HasSuffix(name, zipExt)
+}
+
+// Splits a virtual path into the path of an archive and the name of its entry.
+// Returns `false` for all other paths.
+func splitArchivePath(path string) (string, string, bool) {
+	sep := zipExt + string(filepath.Separator)
+	offset := 0
+	for {
+		i := strings.Index(path[offset:], sep)
+		if i < 0 {
+			return "", "", false
+		}
+		end := offset + i + len(zipExt)
+		archive := path[:end]
+		if info, err := os.Stat(archive); err == nil && info.Mode().IsRegular() {
+			entry := filepath.ToSlash(path[end+1:])
+			return archive, entry, true
+		}
+		offset = end
+	}
+}
+
+// Virtual paths of all FIT files in an archive
+func archiveFitFiles(archive string) ([]string, error) {
+	r, err := zip.OpenReader(archive)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	var paths []string
+	for _, f := range r.File {
+		if !f.FileInfo().IsDir() && isFitName(f.Name) {
+			paths = append(paths, filepath.Join(archive, filepath.FromSlash(f.Name)))
+		}
+	}
+	return paths, nil
+}
+
+func findArchiveEntry(r *zip.Reader, name string) (*zip.File, error) {
+	for _, f := range r.File {
+		if f.Name == name {
+			return f, nil
+		}
+	}
+	return nil, fmt.Errorf("%s not found in archive", name)
+}
+
+// Returns file info of a file or of an entry of an archive (virtual path)
+func Stat(path string) (fs.FileInfo, error) {
+	archive, name, ok := splitArchivePath(path)
+	if !ok {
+		return os.Stat(path)
+	}
+	r, err := zip.OpenReader(archive)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	f, err := findArchiveEntry(&r.Reader, name)
+	if err != nil {
+		return nil, err
+	}
+	return f.FileInfo(), nil
+}
+
+// Returns file infos of many files at once (indexed by path).
+// Each archive is opened once only. Files, which can't be found, are skipped.
+func StatFiles(paths []string) map[string]fs.FileInfo {
+	infos := make(map[string]fs.FileInfo, len(paths))
+	// paths of entries, indexed by archive and name
+	entries := make(map[string]map[string]string)
+	for _, path := range paths {
+		if archive, name, ok := splitArchivePath(path); ok {
+			if entries[archive] == nil {
+				entries[archive] = make(map[string]string)
+			}
+			entries[archive][name] = path
+			continue
+		}
+		if info, err := os.Stat(path); err == nil {
+			infos[path] = info
+		}
+	}
+	for archive, names := range entries {
+		r, err := zip.OpenReader(archive)
+		if err != nil {
+			continue
+		}
+		for _, f := range r.File {
+			if path, ok := names[f.Name]; ok {
+				infos[path] = f.FileInfo()
+			}
+		}
+		r.Close()
+	}
+	return infos
+}
+
+type readCloser struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (rc *readCloser) Close() error {
+	var err error
+	// close in reverse order of opening
+	for i := len(rc.closers) - 1; i >= 0; i-- {
+		if e := rc.closers[i].Close(); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// Opens a file or an entry of an archive (virtual path) to read its raw content,
+// which might be compressed
+func openRaw(path string) (io.ReadCloser, error) {
+	archive, name, ok := splitArchivePath(path)
+	if !ok {
+		return os.Open(path)
+	}
+	r, err := zip.OpenReader(archive)
+	if err != nil {
+		return nil, err
+	}
+	f, err := findArchiveEntry(&r.Reader, name)
+	if err != nil {
+		r.Close()
+		return nil, err
+	}
+	entry, err := f.Open()
+	if err != nil {
+		r.Close()
+		return nil, err
+	}
+	return &readCloser{Reader: entry, closers: []io.Closer{r, entry}}, nil
+}
+
+// Opens a FIT file to read its (decompressed) content.
+// Supports compressed files (`.fit.gz`) and entries of archives (virtual paths).
+func Open(path string) (io.ReadCloser, error) {
+	raw, err := openRaw(path)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(path, gzExt) {
+		return raw, nil
+	}
+	gz, err := gzip.NewReader(raw)
+	if err != nil {
+		raw.Close()
+		return nil, fmt.Errorf("failed to decompress %s: %v", path, err)
+	}
+	return &readCloser{Reader: gz, closers: []io.Closer{raw, gz}}, nil
+}
@@ -0,0 +1,135 @@
+package fit
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func gzipped(t *testing.T, content string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write([]byte(content)); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// Creates a zip archive with given entries (name -> content)
+func createZip(t *testing.T, path string, entries map[string][]byte) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	w := zip.NewWriter(f)
+	for name, content := range entries {
+		e, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := e.Write(content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readAll(t *testing.T, path string) string {
+	t.Helper()
+	r, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	content, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
+
+func TestArchives(t *testing.T) {
+	root := t.TempDir()
+	createFiles(t, root, "a.fit")
+	if err := os.WriteFile(filepath.Join(root, "b.fit.gz"), gzipped(t, "b"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	createZip(t, filepath.Join(root, "export.zip"), map[string][]byte{
+		"activities/c.fit":    []byte("c"),
+		"activities/d.fit.gz": gzipped(t, "d"),
+		"activities/e.txt":    []byte("e"),
+		"profile.json":        []byte("{}"),
+	})
+
+	paths, err := GetFitFilePaths(root, ImportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"a.fit",
+		"b.fit.gz",
+		"export.zip/activities/c.fit",
+		"export.zip/activities/d.fit.gz",
+	}
+	if result := relPaths(t, root, paths); !slices.Equal(result, expected) {
+		t.Errorf("Expected: %v, Got: %v", expected, result)
+	}
+
+	t.Run("exclude entries of archive", func(t *testing.T) {
+		paths, err := GetFitFilePaths(filepath.Join(root, "export.zip"), ImportOptions{Exclude: []string{"*.gz"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := []string{"export.zip/activities/c.fit"}
+		if result := relPaths(t, root, paths); !slices.Equal(result, expected) {
+			t.Errorf("Expected: %v, Got: %v", expected, result)
+		}
+	})
+
+	t.Run("open", func(t *testing.T) {
+		tests := []struct {
+			path     string
+			expected string
+		}{
+			{"b.fit.gz", "b"},
+			{"export.zip/activities/c.fit", "c"},
+			{"export.zip/activities/d.fit.gz", "d"},
+		}
+		for _, tt := range tests {
+			if result := readAll(t, filepath.Join(root, tt.path)); result != tt.expected {
+				t.Errorf("%s: Expected: %q, Got: %q", tt.path, tt.expected, result)
+			}
+		}
+		if _, err := Open(filepath.Join(root, "export.zip", "missing.fit")); err == nil {
+			t.Error("Expected error for missing entry")
+		}
+	})
+
+	t.Run("stat", func(t *testing.T) {
+		virtual := filepath.Join(root, "export.zip", "activities", "c.fit")
+		info, err := Stat(virtual)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Size() != 1 {
+			t.Errorf("Expected size: 1, Got: %d", info.Size())
+		}
+		infos := StatFiles([]string{virtual, filepath.Join(root, "a.fit"), filepath.Join(root, "missing.fit")})
+		if len(infos) != 2 || infos[virtual] == nil {
+			t.Errorf("Expected infos of 2 files, Got: %v", infos)
+		}
+	})
+}
@@ -0,0 +1,683 @@
+package fit
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/muktihari/fit/decoder"
+	"github.com/muktihari/fit/encoder"
+	"github.com/muktihari/fit/profile/basetype"
+	"github.com/muktihari/fit/profile/filedef"
+	"github.com/muktihari/fit/profile/mesgdef"
+	"github.com/muktihari/fit/profile/typedef"
+	"github.com/muktihari/fit/proto"
+	"github.com/sectore/fit-activities-tui/internal/common"
+)
+
+// Editing of FIT activities (e.g. crop) is done on its messages to keep all data
+// of a device, which are not part of `common.ActivityData` (e.g. device infos, events).
+// Totals of laps, sessions and the activity are recalculated by records (see `RecomputeTotals`).
+
+// Reads the (first) activity of a FIT file
+func ReadActivity(path string) (*filedef.Activity, error) {
+	if fileExt(path) != fitExt {
+		return nil, fmt.Errorf("FIT file expected (file %s)", path)
+	}
+	f, err := Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	lis := filedef.NewListener()
+	defer lis.Close()
+
+	dec := decoder.New(f,
+		decoder.WithMesgListener(lis),
+		decoder.WithBroadcastOnly(),
+	)
+	if _, err := dec.Decode(); err != nil {
+		return nil, err
+	}
+	act, ok := lis.File().(*filedef.Activity)
+	if !ok {
+		return nil, fmt.Errorf("no Activity found (file %s)", path)
+	}
+	if len(act.Records) <= 0 {
+		return nil, fmt.Errorf("no Records found (file %s)", path)
+	}
+	return act, nil
+}
+
+// Writes an activity as FIT file. Sizes and CRCs are calculated by the encoder.
+// Developer fields need protocol version 2.0.
+func WriteActivity(w io.Writer, act *filedef.Activity) error {
+	fit := act.ToFIT(nil)
+	if len(act.DeveloperDataIds) > 0 {
+		fit.FileHeader.ProtocolVersion = proto.V2
+	}
+	return encoder.New(w).Encode(&fit)
+}
+
+// Keeps records of given range (incl. `from` and `to`) only.
+// Distances of records start at 0 again. Events outside of this range are removed.
+// Totals are recalculated (see `RecomputeTotals`).
+func CropActivity(act *filedef.Activity, from, to int) error {
+	if from < 0 || to >= len(act.Records) || from >= to {
+		return fmt.Errorf("invalid range of records to crop: %d-%d (of %d)", from+1, to+1, len(act.Records))
+	}
+	act.Records = act.Records[from : to+1]
+
+	// distances start at 0
+	var offset uint32
+	for _, r := range act.Records {
+		if r.Distance != basetype.Uint32Invalid {
+			offset = r.Distance
+			break
+		}
+	}
+	for _, r := range act.Records {
+		if r.Distance != basetype.Uint32Invalid {
+			r.Distance -= min(offset, r.Distance)
+		}
+	}
+
+	start, finish := recordsTimeRange(act.Records)
+	var events []*mesgdef.Event
+	for _, e := range act.Events {
+		if e.Timestamp.IsZero() || (!e.Timestamp.Before(start) && !e.Timestamp.After(finish)) {
+			events = append(events, e)
+		}
+	}
+	act.Events = addTimerEvents(events, start, finish)
+
+	return RecomputeTotals(act)
+}
+
+func timerEvent(t time.Time, eventType typedef.EventType) *mesgdef.Event {
+	return mesgdef.NewEvent(nil).
+		SetTimestamp(t).
+		SetEvent(typedef.EventTimer).
+		SetEventType(eventType).
+		SetEventGroup(0)
+}
+
+// Adds timer events to start the timer at `start` and to stop it at `finish`,
+// if the first (last) timer event of given events doesn't start (stop) the timer already.
+// Events (e.g. of a cropped activity) are expected to be in range of `start` and `finish`.
+func addTimerEvents(events []*mesgdef.Event, start, finish time.Time) []*mesgdef.Event {
+	var first, last *mesgdef.Event
+	for _, e := range events {
+		if e.Event != typedef.EventTimer {
+			continue
+		}
+		if first == nil {
+			first = e
+		}
+		last = e
+	}
+	if first == nil || first.EventType != typedef.EventTypeStart || first.Timestamp.After(start) {
+		events = append([]*mesgdef.Event{timerEvent(start, typedef.EventTypeStart)}, events...)
+	}
+	if last == nil || (last.EventType != typedef.EventTypeStop && last.EventType != typedef.EventTypeStopAll) ||
+		last.Timestamp.Before(finish) {
+		events = append(events, timerEvent(finish, typedef.EventTypeStopAll))
+	}
+	return events
+}
+
+// Time of the first and the last record with a timestamp
+func recordsTimeRange(records []*mesgdef.Record) (time.Time, time.Time) {
+	var start, finish time.Time
+	for _, r := range records {
+		if r.Timestamp.IsZero() {
+			continue
+		}
+		if start.IsZero() {
+			start = r.Timestamp
+		}
+		finish = r.Timestamp
+	}
+	return start, finish
+}
+
+// Splits records into consecutive blocks by end times (e.g. of laps).
+// Each record belongs to the first block, which ends at or after its time.
+// Records after the last end belong to the last block.
+// Returns indexes of the first and last record of each block (-1 for empty blocks).
+func partitionRecords(records []*mesgdef.Record, ends []time.Time) [][2]int {
+	blocks := make([][2]int, len(ends))
+	for i := range blocks {
+		blocks[i] = [2]int{-1, -1}
+	}
+	block := 0
+	for i, r := range records {
+		for !r.Timestamp.IsZero() && block < len(ends)-1 && r.Timestamp.After(ends[block]) {
+			block++
+		}
+		if blocks[block][0] < 0 {
+			blocks[block][0] = i
+		}
+		blocks[block][1] = i
+	}
+	return blocks
+}
+
+// Totals of a range of records
+type recordTotals struct {
+	start, finish    time.Time
+	elapsed, timer   uint32
+	distance         uint32
+	data             common.ActivityData
+	startPos, endPos *mesgdef.Record
+}
+
+// Calculates totals of records. `prev` is the last record before (optional)
+// to count time and distance between both ranges.
+func totalsOfRecords(records []*mesgdef.Record, prev *mesgdef.Record) recordTotals {
+	all := records
+	if prev != nil {
+		all = append([]*mesgdef.Record{prev}, records...)
+	}
+	totals := recordTotals{
+		data: common.SummarizeRecords(parseRecords(records)),
+	}
+	totals.start, totals.finish = recordsTimeRange(all)
+
+	duration := common.DurationOfRecords(parseRecords(all))
+	if duration.Total != nil && duration.Active != nil {
+		totals.elapsed = duration.Total.Value
+		totals.timer = duration.Active.Value
+	}
+
+	first, last := basetype.Uint32Invalid, basetype.Uint32Invalid
+	for _, r := range all {
+		if r.Distance == basetype.Uint32Invalid {
+			continue
+		}
+		if first == basetype.Uint32Invalid {
+			first = r.Distance
+		}
+		last = r.Distance
+	}
+	if first != basetype.Uint32Invalid && last >= first {
+		totals.distance = last - first
+	}
+
+	for _, r := range records {
+		if r.PositionLat == basetype.Sint32Invalid || r.PositionLong == basetype.Sint32Invalid {
+			continue
+		}
+		if totals.startPos == nil {
+			totals.startPos = r
+		}
+		totals.endPos = r
+	}
+	return totals
+}
+
+// Values of a FIT message of optional values (invalid if `nil`)
+
+func speedValue(s *common.Speed) uint32 {
+	if s == nil {
+		return basetype.Uint32Invalid
+	}
+	return uint32(math.Round(float64(s.Value)))
+}
+
+func speed16Value(s *common.Speed) uint16 {
+	v := speedValue(s)
+	if v == basetype.Uint32Invalid || v > math.MaxUint16-1 {
+		return basetype.Uint16Invalid
+	}
+	return uint16(v)
+}
+
+func altitudeValue(a *common.Altitude) uint32 {
+	if a == nil {
+		return basetype.Uint32Invalid
+	}
+	// scale 5, offset 500
+	return uint32(math.Round((a.Value + 500) * 5))
+}
+
+func elevationValue(e *common.Elevation) uint16 {
+	if e == nil {
+		return basetype.Uint16Invalid
+	}
+	return e.Value
+}
+
+func heartrateValue(h *common.Heartrate) uint8 {
+	if h == nil {
+		return basetype.Uint8Invalid
+	}
+	return h.Value
+}
+
+func cadenceValue(c *common.Cadence) uint8 {
+	if c == nil {
+		return basetype.Uint8Invalid
+	}
+	return c.Value
+}
+
+func powerValue(p *common.Power) uint16 {
+	if p == nil {
+		return basetype.Uint16Invalid
+	}
+	return p.Value
+}
+
+func temperatureValue(t *common.Temperature) int8 {
+	if t == nil {
+		return basetype.Sint8Invalid
+	}
+	return t.Value
+}
+
+func positionValues(r *mesgdef.Record) (int32, int32) {
+	if r == nil {
+		return basetype.Sint32Invalid, basetype.Sint32Invalid
+	}
+	return r.PositionLat, r.PositionLong
+}
+
+func applyTotalsToLap(l *mesgdef.Lap, t recordTotals) {
+	l.StartTime = t.start
+	l.Timestamp = t.finish
+	l.TotalElapsedTime = t.elapsed
+	l.TotalTimerTime = t.timer
+	l.TotalDistance = t.distance
+	l.EnhancedAvgSpeed = speedValue(t.data.Speed.Avg)
+	l.EnhancedMaxSpeed = speedValue(t.data.Speed.Max)
+	l.AvgSpeed = speed16Value(t.data.Speed.Avg)
+	l.MaxSpeed = speed16Value(t.data.Speed.Max)
+	l.EnhancedMinAltitude = altitudeValue(t.data.Altitude.Min)
+	l.EnhancedMaxAltitude = altitudeValue(t.data.Altitude.Max)
+	l.TotalAscent = elevationValue(t.data.Elevation.Ascents)
+	l.TotalDescent = elevationValue(t.data.Elevation.Descents)
+	l.AvgHeartRate = heartrateValue(t.data.Heartrate.Avg)
+	l.MinHeartRate = heartrateValue(t.data.Heartrate.Min)
+	l.MaxHeartRate = heartrateValue(t.data.Heartrate.Max)
+	l.AvgCadence = cadenceValue(t.data.Cadence.Avg)
+	l.MaxCadence = cadenceValue(t.data.Cadence.Max)
+	l.AvgPower = powerValue(t.data.Power.Avg)
+	l.MaxPower = powerValue(t.data.Power.Max)
+	l.NormalizedPower = powerValue(t.data.Power.Normalized)
+	l.AvgTemperature = temperatureValue(t.data.Temperature.Avg)
+	l.MaxTemperature = temperatureValue(t.data.Temperature.Max)
+	l.StartPositionLat, l.StartPositionLong = positionValues(t.startPos)
+	l.EndPositionLat, l.EndPositionLong = positionValues(t.endPos)
+}
+
+func applyTotalsToSession(s *mesgdef.Session, t recordTotals) {
+	s.StartTime = t.start
+	s.Timestamp = t.finish
+	s.TotalElapsedTime = t.elapsed
+	s.TotalTimerTime = t.timer
+	s.TotalDistance = t.distance
+	s.EnhancedAvgSpeed = speedValue(t.data.Speed.Avg)
+	s.EnhancedMaxSpeed = speedValue(t.data.Speed.Max)
+	s.AvgSpeed = speed16Value(t.data.Speed.Avg)
+	s.MaxSpeed = speed16Value(t.data.Speed.Max)
+	s.EnhancedMinAltitude = altitudeValue(t.data.Altitude.Min)
+	s.EnhancedMaxAltitude = altitudeValue(t.data.Altitude.Max)
+	s.TotalAscent = elevationValue(t.data.Elevation.Ascents)
+	s.TotalDescent = elevationValue(t.data.Elevation.Descents)
+	s.AvgHeartRate = heartrateValue(t.data.Heartrate.Avg)
+	s.MinHeartRate = heartrateValue(t.data.Heartrate.Min)
+	s.MaxHeartRate = heartrateValue(t.data.Heartrate.Max)
+	s.AvgCadence = cadenceValue(t.data.Cadence.Avg)
+	s.MaxCadence = cadenceValue(t.data.Cadence.Max)
+	s.AvgPower = powerValue(t.data.Power.Avg)
+	s.MaxPower = powerValue(t.data.Power.Max)
+	s.NormalizedPower = powerValue(t.data.Power.Normalized)
+	s.AvgTemperature = temperatureValue(t.data.Temperature.Avg)
+	s.MaxTemperature = temperatureValue(t.data.Temperature.Max)
+	s.StartPositionLat, s.StartPositionLong = positionValues(t.startPos)
+	s.EndPositionLat, s.EndPositionLong = positionValues(t.endPos)
+}
+
+// Totals of each block of records (see `partitionRecords`). Empty blocks are skipped.
+func totalsOfBlocks(records []*mesgdef.Record, ends []time.Time) ([]recordTotals, []int) {
+	var totals []recordTotals
+	var indexes []int
+	for i, block := range partitionRecords(records, ends) {
+		if block[0] < 0 {
+			continue
+		}
+		var prev *mesgdef.Record
+		if block[0] > 0 {
+			prev = records[block[0]-1]
+		}
+		totals = append(totals, totalsOfRecords(records[block[0]:block[1]+1], prev))
+		indexes = append(indexes, i)
+	}
+	return totals, indexes
+}
+
+// Recalculates totals of laps, sessions and the activity by its records:
+// times, distances, speed, elevation, heartrate, cadence, power and temperature.
+// Laps and sessions without any records are removed.
+// Other values (e.g. calories) are kept as they are.
+func RecomputeTotals(act *filedef.Activity) error {
+	if len(act.Records) <= 0 {
+		return fmt.Errorf("no Records found")
+	}
+
+	// laps
+	var lapEnds []time.Time
+	for _, l := range act.Laps {
+		lapEnds = append(lapEnds, l.Timestamp)
+	}
+	if len(act.Laps) <= 0 {
+		lap := mesgdef.NewLap(nil).
+			SetEvent(typedef.EventLap).
+			SetEventType(typedef.EventTypeStop)
+		act.Laps = []*mesgdef.Lap{lap}
+		lapEnds = []time.Time{{}}
+	}
+	lapTotals, lapIndexes := totalsOfBlocks(act.Records, lapEnds)
+	var laps []*mesgdef.Lap
+	for i, index := range lapIndexes {
+		l := act.Laps[index]
+		applyTotalsToLap(l, lapTotals[i])
+		laps = append(laps, l)
+	}
+	act.Laps = laps
+
+	// sessions
+	var sessionEnds []time.Time
+	for _, s := range act.Sessions {
+		sessionEnds = append(sessionEnds, s.Timestamp)
+	}
+	if len(act.Sessions) <= 0 {
+		session := mesgdef.NewSession(nil).
+			SetEvent(typedef.EventSession).
+			SetEventType(typedef.EventTypeStop).
+			SetSport(typedef.SportGeneric)
+		act.Sessions = []*mesgdef.Session{session}
+		sessionEnds = []time.Time{{}}
+	}
+	sessionTotals, sessionIndexes := totalsOfBlocks(act.Records, sessionEnds)
+	var sessions []*mesgdef.Session
+	lapIndex := 0
+	for i, index := range sessionIndexes {
+		s := act.Sessions[index]
+		applyTotalsToSession(s, sessionTotals[i])
+		// laps, which end within the session (last session takes all remaining laps)
+		s.FirstLapIndex = uint16(lapIndex)
+		s.NumLaps = 0
+		for lapIndex < len(act.Laps) &&
+			(i == len(sessionIndexes)-1 || !act.Laps[lapIndex].Timestamp.After(s.Timestamp)) {
+			s.NumLaps++
+			lapIndex++
+		}
+		sessions = append(sessions, s)
+	}
+	act.Sessions = sessions
+
+	// activity
+	if act.Activity == nil {
+		act.Activity = mesgdef.NewActivity(nil).
+			SetType(typedef.ActivityManual).
+			SetEvent(typedef.EventActivity).
+			SetEventType(typedef.EventTypeStop)
+	}
+	_, finish := recordsTimeRange(act.Records)
+	if !act.Activity.LocalTimestamp.IsZero() && !act.Activity.Timestamp.IsZero() {
+		// keep offset of local time
+		offset := act.Activity.LocalTimestamp.Sub(act.Activity.Timestamp)
+		act.Activity.LocalTimestamp = finish.Add(offset)
+	}
+	act.Activity.Timestamp = finish
+	act.Activity.NumSessions = uint16(len(act.Sessions))
+	act.Activity.TotalTimerTime = 0
+	for _, s := range act.Sessions {
+		act.Activity.TotalTimerTime += s.TotalTimerTime
+	}
+	return nil
+}
+
+// First valid distance of records
+func firstDistance(records []*mesgdef.Record) (uint32, bool) {
+	for _, r := range records {
+		if r.Distance != basetype.Uint32Invalid {
+			return r.Distance, true
+		}
+	}
+	return 0, false
+}
+
+// Last valid distance and position of records
+func lastDistance(records []*mesgdef.Record) (uint32, *mesgdef.Record, bool) {
+	for i := len(records) - 1; i >= 0; i-- {
+		if records[i].Distance != basetype.Uint32Invalid {
+			return records[i].Distance, records[i], true
+		}
+	}
+	return 0, nil, false
+}
+
+// Distance (cm) between positions of two records (0 if there are no positions)
+func distanceBetween(a, b *mesgdef.Record) uint32 {
+	p1 := semicirclesPosition(positionValues(a))
+	p2 := semicirclesPosition(positionValues(b))
+	if p1 == nil || p2 == nil {
+		return 0
+	}
+	return uint32(math.Round(p1.DistanceTo(*p2) * 100))
+}
+
+// Merges activities (e.g. of a ride split by a battery swap) into one activity.
+// Messages of the first activity (by time) are used for file id, device infos etc.
+// Records, laps and events are joined in order of time. Distances continue
+// where the previous activity ends, if a device starts counting from 0 again.
+// Consecutive sessions of the same sport are combined into one session.
+// Totals are recalculated (see `RecomputeTotals`).
+func MergeActivities(acts []*filedef.Activity) (*filedef.Activity, error) {
+	if len(acts) < 2 {
+		return nil, fmt.Errorf("at least 2 activities are needed to merge")
+	}
+	acts = slices.Clone(acts)
+	slices.SortStableFunc(acts, func(a, b *filedef.Activity) int {
+		startA, _ := recordsTimeRange(a.Records)
+		startB, _ := recordsTimeRange(b.Records)
+		return startA.Compare(startB)
+	})
+
+	merged := acts[0]
+	for _, act := range acts[1:] {
+		// continue distances of a device, which starts counting from 0 again
+		if last, lastRecord, ok := lastDistance(merged.Records); ok {
+			if first, ok := firstDistance(act.Records); ok && first < last {
+				offset := last - first + distanceBetween(lastRecord, act.Records[0])
+				for _, r := range act.Records {
+					if r.Distance != basetype.Uint32Invalid {
+						r.Distance += offset
+					}
+				}
+			}
+		}
+		if err := mergeDeveloperData(merged, act); err != nil {
+			return nil, err
+		}
+		merged.Records = append(merged.Records, act.Records...)
+		merged.Laps = append(merged.Laps, act.Laps...)
+		merged.Events = append(merged.Events, act.Events...)
+		merged.Lengths = append(merged.Lengths, act.Lengths...)
+
+		for _, s := range act.Sessions {
+			// combine consecutive sessions of the same sport
+			if n := len(merged.Sessions); n > 0 && merged.Sessions[n-1].Sport == s.Sport {
+				merged.Sessions[n-1].Timestamp = s.Timestamp
+				continue
+			}
+			merged.Sessions = append(merged.Sessions, s)
+		}
+	}
+
+	// records of overlapping activities
+	slices.SortStableFunc(merged.Records, func(a, b *mesgdef.Record) int {
+		return a.Timestamp.Compare(b.Timestamp)
+	})
+	slices.SortStableFunc(merged.Laps, func(a, b *mesgdef.Lap) int {
+		return a.Timestamp.Compare(b.Timestamp)
+	})
+	slices.SortStableFunc(merged.Events, func(a, b *mesgdef.Event) int {
+		return a.Timestamp.Compare(b.Timestamp)
+	})
+
+	if err := RecomputeTotals(merged); err != nil {
+		return nil, err
+	}
+	return merged, nil
+}
+
+// Adds developer data (e.g. of Connect IQ apps or Stryd) of an activity to the merged activity.
+// Developer data of the same app is merged, indexes of other apps are changed if they are
+// already used by the merged activity. Developer fields of the activity are changed accordingly.
+func mergeDeveloperData(merged, act *filedef.Activity) error {
+	indexes := map[uint8]uint8{}
+	used := map[uint8]bool{}
+	for _, d := range merged.DeveloperDataIds {
+		used[d.DeveloperDataIndex] = true
+	}
+	for _, d := range act.DeveloperDataIds {
+		if i := slices.IndexFunc(merged.DeveloperDataIds, func(m *mesgdef.DeveloperDataId) bool {
+			return sameDeveloperApp(m, d)
+		}); i >= 0 {
+			indexes[d.DeveloperDataIndex] = merged.DeveloperDataIds[i].DeveloperDataIndex
+			continue
+		}
+		index := d.DeveloperDataIndex
+		for used[index] {
+			// 255 is invalid
+			if index++; index == basetype.Uint8Invalid {
+				return fmt.Errorf("too many developer data ids to merge")
+			}
+		}
+		used[index] = true
+		indexes[d.DeveloperDataIndex] = index
+		d.DeveloperDataIndex = index
+		merged.DeveloperDataIds = append(merged.DeveloperDataIds, d)
+	}
+
+	for _, f := range act.FieldDescriptions {
+		index, ok := indexes[f.DeveloperDataIndex]
+		if !ok {
+			continue
+		}
+		f.DeveloperDataIndex = index
+		// fields of the same app are described already
+		if !slices.ContainsFunc(merged.FieldDescriptions, func(m *mesgdef.FieldDescription) bool {
+			return m.DeveloperDataIndex == f.DeveloperDataIndex && m.FieldDefinitionNumber == f.FieldDefinitionNumber
+		}) {
+			merged.FieldDescriptions = append(merged.FieldDescriptions, f)
+		}
+	}
+
+	for _, r := range act.Records {
+		r.DeveloperFields = remapDeveloperFields(r.DeveloperFields, indexes)
+	}
+	for _, l := range act.Laps {
+		l.DeveloperFields = remapDeveloperFields(l.DeveloperFields, indexes)
+	}
+	for _, e := range act.Events {
+		e.DeveloperFields = remapDeveloperFields(e.DeveloperFields, indexes)
+	}
+	for _, l := range act.Lengths {
+		l.DeveloperFields = remapDeveloperFields(l.DeveloperFields, indexes)
+	}
+	for _, s := range act.Sessions {
+		s.DeveloperFields = remapDeveloperFields(s.DeveloperFields, indexes)
+	}
+	return nil
+}
+
+// Checks whether developer data ids belong to the same app
+func sameDeveloperApp(a, b *mesgdef.DeveloperDataId) bool {
+	return slices.Equal(a.ApplicationId, b.ApplicationId) &&
+		slices.Equal(a.DeveloperId, b.DeveloperId) &&
+		a.ManufacturerId == b.ManufacturerId
+}
+
+// Changes indexes of developer fields. Fields without a developer data id are removed
+// (they can't be encoded).
+func remapDeveloperFields(fields []proto.DeveloperField, indexes map[uint8]uint8) []proto.DeveloperField {
+	var result []proto.DeveloperField
+	for _, f := range fields {
+		if index, ok := indexes[f.DeveloperDataIndex]; ok {
+			f.DeveloperDataIndex = index
+			result = append(result, f)
+		}
+	}
+	return result
+}
+
+// Pauses shorter than this are ignored to split an activity (see `LongestPause`)
+const DefaultMinSplitPause = 10 * time.Minute
+
+// Copy of an activity, which doesn't share any messages with the original
+func cloneActivity(act *filedef.Activity) *filedef.Activity {
+	fit := act.ToFIT(nil)
+	return filedef.NewActivity(fit.Messages...)
+}
+
+// Splits an activity (e.g. of a morning and an evening ride) into two activities.
+// The first one includes all records before `at`, the second one the record `at`
+// and all following records. Each part needs at least 2 records.
+// The original activity is not changed. Totals are recalculated (see `CropActivity`).
+func SplitActivity(act *filedef.Activity, at int) (*filedef.Activity, *filedef.Activity, error) {
+	noRecords := len(act.Records)
+	if at < 2 || at > noRecords-2 {
+		return nil, nil, fmt.Errorf("invalid record to split at: %d (of %d)", at+1, noRecords)
+	}
+
+	first := cloneActivity(act)
+	if err := CropActivity(first, 0, at-1); err != nil {
+		return nil, nil, err
+	}
+	second := cloneActivity(act)
+	if err := CropActivity(second, at, noRecords-1); err != nil {
+		return nil, nil, err
+	}
+	// FIT files are identified by serial number and time created (e.g. by Strava or Garmin Connect)
+	if start, _ := recordsTimeRange(second.Records); !start.IsZero() {
+		second.FileId.TimeCreated = start
+	}
+	return first, second, nil
+}
+
+// Longest pause between two records. Returns index of the record after the pause.
+// Index is -1, if there are less than 2 records with timestamps.
+func LongestPause(records []*mesgdef.Record) (int, time.Duration) {
+	index, longest := -1, time.Duration(0)
+	var prev time.Time
+	for i, r := range records {
+		if r.Timestamp.IsZero() {
+			continue
+		}
+		if !prev.IsZero() {
+			if pause := r.Timestamp.Sub(prev); index < 0 || pause > longest {
+				index, longest = i, pause
+			}
+		}
+		prev = r.Timestamp
+	}
+	return index, longest
+}
+
+// Index of the record to split an activity at its longest pause (see `SplitActivity`).
+// The pause has to last `minPause` at least.
+func SplitIndexAtPause(act *filedef.Activity, minPause time.Duration) (int, error) {
+	index, pause := LongestPause(act.Records)
+	if index < 0 || pause < minPause {
+		return -1, fmt.Errorf("no pause of at least %s found", minPause)
+	}
+	return index, nil
+}
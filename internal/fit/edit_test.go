@@ -0,0 +1,376 @@
+package fit
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/muktihari/fit/profile/basetype"
+	"github.com/muktihari/fit/profile/filedef"
+	"github.com/muktihari/fit/profile/mesgdef"
+	"github.com/muktihari/fit/profile/typedef"
+	"github.com/muktihari/fit/proto"
+	"github.com/sectore/fit-activities-tui/internal/fit/fittest"
+)
+
+// Writes an activity into a file by `WriteActivity`
+// Timer events (type and seconds since `fittest.StartTime`) of an activity
+type testTimerEvent struct {
+	eventType typedef.EventType
+	seconds   time.Duration
+}
+
+func timerEventsOf(act *filedef.Activity) []testTimerEvent {
+	var events []testTimerEvent
+	for _, e := range act.Events {
+		if e.Event == typedef.EventTimer {
+			events = append(events, testTimerEvent{e.EventType, e.Timestamp.Sub(fittest.StartTime) / time.Second})
+		}
+	}
+	return events
+}
+
+func addTestTimerEvents(act *filedef.Activity, events ...testTimerEvent) {
+	for _, e := range events {
+		act.Events = append(act.Events, timerEvent(fittest.StartTime.Add(e.seconds*time.Second), e.eventType))
+	}
+}
+
+// Activity of `noRecords` records with a lap for every 5 records
+func testFitActivityWithLaps(noRecords int) *filedef.Activity {
+	act := fittest.Activity(noRecords)
+	act.Laps = nil
+	for end := 4; end < noRecords+4; end += 5 {
+		end := min(end, noRecords-1)
+		act.Laps = append(act.Laps, mesgdef.NewLap(nil).
+			SetTimestamp(fittest.StartTime.Add(time.Duration(end)*time.Second)))
+	}
+	return act
+}
+
+func TestCropActivity(t *testing.T) {
+	dir := t.TempDir()
+	original := filepath.Join(dir, "original.fit")
+	act := testFitActivityWithLaps(10)
+	// pause between 4. and 5. record
+	addTestTimerEvents(act,
+		testTimerEvent{typedef.EventTypeStart, 0},
+		testTimerEvent{typedef.EventTypeStop, 3},
+		testTimerEvent{typedef.EventTypeStart, 4},
+		testTimerEvent{typedef.EventTypeStopAll, 9},
+	)
+	fittest.WriteFile(t, original, act)
+	content, err := os.ReadFile(original)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	act, err = ReadActivity(original)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// records 3-7
+	if err := CropActivity(act, 2, 6); err != nil {
+		t.Fatal(err)
+	}
+	cropped := filepath.Join(dir, "cropped.fit")
+	fittest.WriteFile(t, cropped, act)
+
+	if v := Validate(cropped); len(v.Issues) > 0 {
+		t.Fatalf("Expected valid file, Got: %+v", v.Issues)
+	}
+	data, err := ParseFile(cropped)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.NoRecords() != 5 {
+		t.Errorf("Expected 5 records, Got: %d", data.NoRecords())
+	}
+	if first := data.Records[0]; first.Distance == nil || first.Distance.Value != 0 {
+		t.Errorf("Expected distance of first record: 0, Got: %v", first.Distance)
+	}
+	if data.TotalDistance == nil || data.TotalDistance.Value != 2000 {
+		t.Errorf("Expected distance: 20m, Got: %v", data.TotalDistance)
+	}
+	if data.Duration.Total == nil || data.Duration.Total.Value != 4000 {
+		t.Errorf("Expected duration: 4s, Got: %v", data.Duration.Total)
+	}
+	if start := data.StartTime(); start == nil || !start.Value.Equal(fittest.StartTime.Add(2*time.Second)) {
+		t.Errorf("Expected start: %v, Got: %v", fittest.StartTime.Add(2*time.Second), start)
+	}
+	// records 3-5 of 1. lap, 6-7 of 2. lap
+	if len(data.Laps) != 2 {
+		t.Fatalf("Expected 2 laps, Got: %d", len(data.Laps))
+	}
+	expected := []uint32{1000, 1000}
+	for i, lap := range data.Laps {
+		if lap.Distance == nil || lap.Distance.Value != expected[i] {
+			t.Errorf("Expected distance of lap %d: %d, Got: %v", i+1, expected[i], lap.Distance)
+		}
+	}
+	if data.Heartrate.Max == nil || data.Heartrate.Max.Value != 126 {
+		t.Errorf("Expected max. heartrate: 126, Got: %v", data.Heartrate.Max)
+	}
+	// timer is started at first and stopped at last record
+	croppedAct, err := ReadActivity(cropped)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedEvents := []testTimerEvent{
+		{typedef.EventTypeStart, 2},
+		{typedef.EventTypeStop, 3},
+		{typedef.EventTypeStart, 4},
+		{typedef.EventTypeStopAll, 6},
+	}
+	if events := timerEventsOf(croppedAct); !slices.Equal(events, expectedEvents) {
+		t.Errorf("Expected timer events: %v, Got: %v", expectedEvents, events)
+	}
+
+	// original file is untouched
+	after, err := os.ReadFile(original)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(content, after) {
+		t.Errorf("Expected original file to be untouched")
+	}
+}
+
+func TestCropActivityInvalidRange(t *testing.T) {
+	tests := []struct{ from, to int }{
+		{-1, 3},
+		{3, 3},
+		{4, 2},
+		{0, 10},
+	}
+	for _, test := range tests {
+		act := fittest.Activity(10)
+		if err := CropActivity(act, test.from, test.to); err == nil {
+			t.Errorf("Expected error of range %d-%d", test.from, test.to)
+		}
+	}
+}
+
+func TestMergeActivities(t *testing.T) {
+	dir := t.TempDir()
+	first := fittest.Activity(5)
+	// second activity starts 10s after the first one and counts distances from 0 again
+	second := fittest.Activity(3)
+	for _, r := range second.Records {
+		r.Timestamp = r.Timestamp.Add(14 * time.Second)
+	}
+	for _, l := range second.Laps {
+		l.Timestamp = l.Timestamp.Add(14 * time.Second)
+	}
+	for _, s := range second.Sessions {
+		s.Timestamp = s.Timestamp.Add(14 * time.Second)
+	}
+
+	// order of activities doesn't matter
+	act, err := MergeActivities([]*filedef.Activity{second, first})
+	if err != nil {
+		t.Fatal(err)
+	}
+	merged := filepath.Join(dir, "merged.fit")
+	fittest.WriteFile(t, merged, act)
+
+	if v := Validate(merged); len(v.Issues) > 0 {
+		t.Fatalf("Expected valid file, Got: %+v", v.Issues)
+	}
+	data, err := ParseFile(merged)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.NoRecords() != 8 {
+		t.Errorf("Expected 8 records, Got: %d", data.NoRecords())
+	}
+	// 20m of first activity + 10m of second activity (no positions -> no gap)
+	if data.TotalDistance == nil || data.TotalDistance.Value != 3000 {
+		t.Errorf("Expected distance: 30m, Got: %v", data.TotalDistance)
+	}
+	if last := data.Records[7]; last.Distance == nil || last.Distance.Value != 3000 {
+		t.Errorf("Expected distance of last record: 30m, Got: %v", last.Distance)
+	}
+	if data.NoSessions != 1 {
+		t.Errorf("Expected 1 session, Got: %d", data.NoSessions)
+	}
+	if len(data.Laps) != 2 {
+		t.Errorf("Expected 2 laps, Got: %d", len(data.Laps))
+	}
+	if data.Duration.Total == nil || data.Duration.Total.Value != 16000 {
+		t.Errorf("Expected duration: 16s, Got: %v", data.Duration.Total)
+	}
+}
+
+func TestMergeActivitiesTooFew(t *testing.T) {
+	if _, err := MergeActivities([]*filedef.Activity{fittest.Activity(3)}); err == nil {
+		t.Errorf("Expected error of a single activity")
+	}
+}
+
+// Adds a developer field (e.g. power of a Stryd) of an app to all records
+// using developer data index 0
+func addTestDeveloperField(act *filedef.Activity, app byte, name string, value uint16) {
+	act.DeveloperDataIds = append(act.DeveloperDataIds, mesgdef.NewDeveloperDataId(nil).
+		SetApplicationId(slices.Repeat([]byte{app}, 16)).
+		SetDeveloperDataIndex(0))
+	act.FieldDescriptions = append(act.FieldDescriptions, mesgdef.NewFieldDescription(nil).
+		SetDeveloperDataIndex(0).
+		SetFieldDefinitionNumber(0).
+		SetFitBaseTypeId(basetype.Uint16).
+		SetFieldName([]string{name}))
+	for _, r := range act.Records {
+		r.DeveloperFields = append(r.DeveloperFields, proto.DeveloperField{
+			Num:                0,
+			DeveloperDataIndex: 0,
+			Value:              proto.Uint16(value),
+		})
+	}
+}
+
+func TestMergeActivitiesDeveloperData(t *testing.T) {
+	tests := []struct {
+		name string
+		// apps of both activities
+		apps [2]byte
+		// expected developer data indexes of records of both activities
+		indexes          [2]uint8
+		developerDataIds int
+	}{
+		{"different apps", [2]byte{1, 2}, [2]uint8{0, 1}, 2},
+		{"same app", [2]byte{1, 1}, [2]uint8{0, 0}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			first := fittest.Activity(3)
+			addTestDeveloperField(first, tt.apps[0], "power", 250)
+			second := fittest.Activity(3)
+			for _, r := range second.Records {
+				r.Timestamp = r.Timestamp.Add(10 * time.Second)
+			}
+			addTestDeveloperField(second, tt.apps[1], "stryd_power", 300)
+
+			act, err := MergeActivities([]*filedef.Activity{first, second})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var buf bytes.Buffer
+			if err := WriteActivity(&buf, act); err != nil {
+				t.Fatal(err)
+			}
+			path := filepath.Join(t.TempDir(), "merged.fit")
+			if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			merged, err := ReadActivity(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(merged.DeveloperDataIds) != tt.developerDataIds {
+				t.Errorf("Expected developer data ids: %d, Got: %d", tt.developerDataIds, len(merged.DeveloperDataIds))
+			}
+			if len(merged.FieldDescriptions) != tt.developerDataIds {
+				t.Errorf("Expected field descriptions: %d, Got: %d", tt.developerDataIds, len(merged.FieldDescriptions))
+			}
+			for i, r := range merged.Records {
+				part := i / 3
+				values := []uint16{250, 300}
+				if len(r.DeveloperFields) != 1 {
+					t.Fatalf("Expected a developer field of record %d, Got: %+v", i, r.DeveloperFields)
+				}
+				f := r.DeveloperFields[0]
+				if f.DeveloperDataIndex != tt.indexes[part] || f.Value.Uint16() != values[part] {
+					t.Errorf("Expected developer field of record %d: index %d, value %d, Got: index %d, value %d",
+						i, tt.indexes[part], values[part], f.DeveloperDataIndex, f.Value.Uint16())
+				}
+			}
+		})
+	}
+}
+
+func TestSplitActivity(t *testing.T) {
+	dir := t.TempDir()
+	act := testFitActivityWithLaps(10)
+	// pause of 20min before 6. record
+	pause := 20 * time.Minute
+	for _, r := range act.Records[5:] {
+		r.Timestamp = r.Timestamp.Add(pause)
+	}
+	act.Laps[1].Timestamp = act.Laps[1].Timestamp.Add(pause)
+	act.Sessions[0].Timestamp = act.Sessions[0].Timestamp.Add(pause)
+	addTestTimerEvents(act,
+		testTimerEvent{typedef.EventTypeStart, 0},
+		testTimerEvent{typedef.EventTypeStopAll, 9 + pause/time.Second},
+	)
+
+	at, err := SplitIndexAtPause(act, DefaultMinSplitPause)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if at != 5 {
+		t.Fatalf("Expected split at record 5, Got: %d", at)
+	}
+	first, second, err := SplitActivity(act, at)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// timer of each part is started at its first and stopped at its last record
+	expectedEvents := [][]testTimerEvent{
+		{{typedef.EventTypeStart, 0}, {typedef.EventTypeStopAll, 4}},
+		{{typedef.EventTypeStart, 5 + pause/time.Second}, {typedef.EventTypeStopAll, 9 + pause/time.Second}},
+	}
+	for i, part := range []*filedef.Activity{first, second} {
+		if events := timerEventsOf(part); !slices.Equal(events, expectedEvents[i]) {
+			t.Errorf("part %d: Expected timer events: %v, Got: %v", i+1, expectedEvents[i], events)
+		}
+		path := filepath.Join(dir, "part.fit")
+		fittest.WriteFile(t, path, part)
+		if v := Validate(path); len(v.Issues) > 0 {
+			t.Fatalf("part %d: Expected valid file, Got: %+v", i+1, v.Issues)
+		}
+		data, err := ParseFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if data.NoRecords() != 5 {
+			t.Errorf("part %d: Expected 5 records, Got: %d", i+1, data.NoRecords())
+		}
+		if data.NoSessions != 1 {
+			t.Errorf("part %d: Expected 1 session, Got: %d", i+1, data.NoSessions)
+		}
+		if data.TotalDistance == nil || data.TotalDistance.Value != 2000 {
+			t.Errorf("part %d: Expected distance: 20m, Got: %v", i+1, data.TotalDistance)
+		}
+		if data.Duration.Total == nil || data.Duration.Total.Value != 4000 {
+			t.Errorf("part %d: Expected duration: 4s, Got: %v", i+1, data.Duration.Total)
+		}
+	}
+	expected := fittest.StartTime.Add(5*time.Second + pause)
+	if !second.FileId.TimeCreated.Equal(expected) {
+		t.Errorf("Expected time created of 2. part: %v, Got: %v", expected, second.FileId.TimeCreated)
+	}
+
+	// original activity is untouched
+	if len(act.Records) != 10 || act.Records[9].Distance != 4500 {
+		t.Errorf("Expected original activity to be untouched")
+	}
+}
+
+func TestSplitActivityInvalid(t *testing.T) {
+	act := fittest.Activity(10)
+	for _, at := range []int{-1, 0, 1, 9, 10} {
+		if _, _, err := SplitActivity(act, at); err == nil {
+			t.Errorf("Expected error of split at %d", at)
+		}
+	}
+	// no long pauses
+	if _, err := SplitIndexAtPause(act, DefaultMinSplitPause); err == nil {
+		t.Errorf("Expected error of missing pause")
+	}
+}
@@ -4,19 +4,49 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 )
 
+// Options to find FIT files
+type ImportOptions struct {
+	// Include files of sub directories
+	Recursive bool
+	// Glob patterns of files or directories to exclude.
+	// Patterns without a path separator are matched against names (e.g. `*.tmp.fit`, `backup`),
+	// all others against full paths (e.g. `/data/**/old/*`).
+	Exclude []string
+}
+
 func isFitFile(file os.FileInfo) bool {
 	return file.Mode().IsRegular() &&
-		strings.HasSuffix(file.Name(), ".fit")
+		isFitName(file.Name())
 }
 
+func isZipFile(file os.FileInfo) bool {
+	return file.Mode().IsRegular() &&
+		isZipName(file.Name())
+}
+
+// Checks a (compressed) FIT file or a FIT file of an archive (virtual path)
 func isValidFitFile(path string) bool {
-	info, err := os.Stat(path)
+	info, err := Stat(path)
 	return err == nil && !info.IsDir() && isFitFile(info)
 }
 
+// Calls `onFile` for each FIT file of an archive, which is not excluded
+func walkArchive(archive string, ex *excluder, onFile func(string)) {
+	paths, err := archiveFitFiles(archive)
+	if err != nil {
+		return
+	}
+	for _, p := range paths {
+		if !ex.excluded(p) {
+			onFile(p)
+		}
+	}
+}
+
 func filesOrError(files []string, path string) ([]string, error) {
 	if len(files) == 0 {
 		return nil, fmt.Errorf("Given path does not include FIT files: %s", path)
@@ -24,32 +54,269 @@ func filesOrError(files []string, path string) ([]string, error) {
 	return files, nil
 }
 
-func GetFitFilePaths(path string) ([]string, error) {
+func hasGlob(path string) bool {
+	return strings.ContainsAny(path, "*?[")
+}
+
+// Converts a glob pattern into a regular expression.
+// In addition to `filepath.Match` it supports `**` to match any number of directories.
+func globToRegexp(pattern string) (*regexp.Regexp, error) {
+	pattern = filepath.ToSlash(pattern)
+	var sb strings.Builder
+	sb.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch c {
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				i++
+				// `**/` matches zero or more directories
+				if i+1 < len(pattern) && pattern[i+1] == '/' {
+					i++
+					sb.WriteString("(?:.*/)?")
+				} else {
+					sb.WriteString(".*")
+				}
+			} else {
+				sb.WriteString("[^/]*")
+			}
+		case '?':
+			sb.WriteString("[^/]")
+		case '[':
+			end := classEnd(pattern[i+1:])
+			if end < 0 {
+				return nil, fmt.Errorf("missing ] in %s", pattern)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("empty character class in %s", pattern)
+			}
+			sb.WriteString(classToRegexp(pattern[i+1 : i+1+end]))
+			i += end + 1
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	sb.WriteString("$")
+	return regexp.Compile(sb.String())
+}
+
+// Index of `]` closing a character class (e.g. 3 of `a-z]`) or -1.
+// Like `filepath.Match`, `]` escaped by `\` is part of the class.
+func classEnd(class string) int {
+	for i := 0; i < len(class); i++ {
+		switch class[i] {
+		case '\\':
+			i++
+		case ']':
+			return i
+		}
+	}
+	return -1
+}
+
+// Converts the body of a character class (e.g. `!a-z` of `[!a-z]`) into a class of a regular expression.
+// Like `filepath.Match` it supports negation (`!` or `^`) and ranges (`a-z`) only,
+// all other characters (incl. `\` escaping the next character) are matched literally.
+func classToRegexp(class string) string {
+	var sb strings.Builder
+	sb.WriteString("[")
+	if strings.HasPrefix(class, "!") || strings.HasPrefix(class, "^") {
+		sb.WriteString("^")
+		class = class[1:]
+	}
+	runes := []rune(class)
+	for i := 0; i < len(runes); i++ {
+		if runes[i] == '\\' && i+1 < len(runes) {
+			i++
+		}
+		sb.WriteString(quoteClassChar(runes[i]))
+		// range, e.g. `a-z`
+		if i+2 < len(runes) && runes[i+1] == '-' {
+			i += 2
+			if runes[i] == '\\' && i+1 < len(runes) {
+				i++
+			}
+			sb.WriteString("-" + quoteClassChar(runes[i]))
+		}
+	}
+	sb.WriteString("]")
+	return sb.String()
+}
+
+// Escapes a character to be matched literally within a class of a regular expression
+func quoteClassChar(r rune) string {
+	if r == '-' {
+		return `\-`
+	}
+	return regexp.QuoteMeta(string(r))
+}
+
+// Directory of a glob pattern without any glob characters, e.g. `/data` of `/data/**/*.fit`
+func globBase(pattern string) string {
+	dir := pattern
+	for hasGlob(dir) {
+		dir = filepath.Dir(dir)
+	}
+	return dir
+}
+
+type excluder struct {
+	names []*regexp.Regexp
+	paths []*regexp.Regexp
+}
+
+func newExcluder(patterns []string) (*excluder, error) {
+	e := &excluder{}
+	for _, p := range patterns {
+		re, err := globToRegexp(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exclude pattern: %v", err)
+		}
+		if strings.ContainsRune(filepath.ToSlash(p), '/') {
+			e.paths = append(e.paths, re)
+		} else {
+			e.names = append(e.names, re)
+		}
+	}
+	return e, nil
+}
+
+func (e *excluder) excluded(path string) bool {
+	name := filepath.Base(path)
+	for _, re := range e.names {
+		if re.MatchString(name) {
+			return true
+		}
+	}
+	slashed := filepath.ToSlash(path)
+	for _, re := range e.paths {
+		if re.MatchString(slashed) {
+			return true
+		}
+	}
+	return false
+}
+
+// Walks a directory and calls `onFile` for each FIT file (incl. FIT files of archives).
+// Symlinks are followed, but each (real) directory is visited once only to avoid cycles.
+func walkDir(dir string, recursive bool, ex *excluder, visited map[string]bool, onFile func(string)) {
+	realDir, err := filepath.EvalSymlinks(dir)
+	if err != nil || visited[realDir] {
+		return
+	}
+	visited[realDir] = true
+
+	entries, _ := os.ReadDir(dir)
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		if ex.excluded(path) {
+			continue
+		}
+		// `os.Stat` follows symlinks
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		if info.IsDir() {
+			if recursive {
+				walkDir(path, recursive, ex, visited, onFile)
+			}
+			continue
+		}
+		if isFitFile(info) {
+			onFile(path)
+			continue
+		}
+		if isZipFile(info) {
+			walkArchive(path, ex, onFile)
+		}
+	}
+}
+
+// Removes duplicated files (e.g. found via symlinks), keeps order
+func uniqueFiles(files []string) []string {
+	seen := make(map[string]bool)
+	var unique []string
+	for _, f := range files {
+		key := f
+		if real, err := filepath.EvalSymlinks(f); err == nil {
+			key = real
+		}
+		if abs, err := filepath.Abs(key); err == nil {
+			key = abs
+		}
+		if !seen[key] {
+			seen[key] = true
+			unique = append(unique, f)
+		}
+	}
+	return unique
+}
+
+func GetFitFilePaths(path string, opts ImportOptions) ([]string, error) {
 	var fitFiles []string
 
+	ex, err := newExcluder(opts.Exclude)
+	if err != nil {
+		return nil, err
+	}
+
 	// 1. Try glob pattern first
-	if strings.ContainsAny(path, "*?[") {
+	if hasGlob(path) {
+		// walked paths are cleaned by `filepath.Join` (e.g. `./data/a.fit` -> `data/a.fit`)
+		path = filepath.Clean(path)
+		// `**` needs to walk all sub directories
+		if strings.Contains(path, "**") {
+			re, err := globToRegexp(path)
+			if err != nil {
+				return nil, fmt.Errorf("invalid glob pattern: %v", err)
+			}
+			walkDir(globBase(path), true, ex, map[string]bool{}, func(p string) {
+				if re.MatchString(filepath.ToSlash(p)) {
+					fitFiles = append(fitFiles, p)
+				}
+			})
+			return filesOrError(uniqueFiles(fitFiles), path)
+		}
+
 		matches, err := filepath.Glob(path)
 		if err != nil {
 			return nil, fmt.Errorf("invalid glob pattern: %v", err)
 		}
 		for _, p := range matches {
+			if ex.excluded(p) {
+				continue
+			}
 			if isValidFitFile(p) {
 				fitFiles = append(fitFiles, p)
+				continue
+			}
+			info, err := os.Stat(p)
+			if err != nil {
+				continue
+			}
+			if isZipFile(info) {
+				walkArchive(p, ex, func(f string) {
+					fitFiles = append(fitFiles, f)
+				})
+				continue
+			}
+			// matched directories are imported as directories
+			if info.IsDir() && opts.Recursive {
+				walkDir(p, true, ex, map[string]bool{}, func(f string) {
+					fitFiles = append(fitFiles, f)
+				})
 			}
 		}
-		return filesOrError(fitFiles, path)
+		return filesOrError(uniqueFiles(fitFiles), path)
 	}
 
 	// 2. Try as directory
 	if fileInfo, err := os.Stat(path); err == nil && fileInfo.IsDir() {
-		entries, _ := os.ReadDir(path)
-		for _, entry := range entries {
-			if info, err := entry.Info(); err == nil && !entry.IsDir() && isFitFile(info) {
-				fitFiles = append(fitFiles, filepath.Join(path, entry.Name()))
-			}
-		}
-		return filesOrError(fitFiles, path)
+		walkDir(path, opts.Recursive, ex, map[string]bool{}, func(p string) {
+			fitFiles = append(fitFiles, p)
+		})
+		return filesOrError(uniqueFiles(fitFiles), path)
 	}
 
 	// 3. Try as single file
@@ -57,5 +324,68 @@ func GetFitFilePaths(path string) ([]string, error) {
 		fitFiles = append(fitFiles, path)
 	}
 
+	// 4. Try as archive
+	if fileInfo, err := os.Stat(path); err == nil && isZipFile(fileInfo) {
+		walkArchive(path, ex, func(p string) {
+			fitFiles = append(fitFiles, p)
+		})
+	}
+
 	return filesOrError(fitFiles, path)
 }
+
+// FIT file found in an import source
+type ImportFile struct {
+	Path string
+	// Root directory of the source the file has been found in
+	Source string
+}
+
+// Root directory of an import source (directory, single file or glob pattern)
+func sourceRoot(source string) string {
+	if hasGlob(source) {
+		return globBase(source)
+	}
+	if info, err := os.Stat(source); err == nil && !info.IsDir() {
+		return filepath.Dir(source)
+	}
+	return filepath.Clean(source)
+}
+
+// Finds FIT files of all given sources (see `GetFitFilePaths`).
+// Files found by more than one source are included once only (with its first source).
+// Returns an error if a source does not include any FIT file.
+func GetImportFiles(sources []string, opts ImportOptions) ([]ImportFile, error) {
+	return collectImportFiles(sources, opts, true)
+}
+
+// Same as `GetImportFiles`, but sources without FIT files are ignored.
+func FindImportFiles(sources []string, opts ImportOptions) []ImportFile {
+	files, _ := collectImportFiles(sources, opts, false)
+	return files
+}
+
+func collectImportFiles(sources []string, opts ImportOptions, strict bool) ([]ImportFile, error) {
+	var files []ImportFile
+	var paths []string
+	sourceOf := make(map[string]string)
+	for _, source := range sources {
+		sourcePaths, err := GetFitFilePaths(source, opts)
+		if err != nil {
+			if strict {
+				return nil, err
+			}
+			continue
+		}
+		for _, p := range sourcePaths {
+			if _, ok := sourceOf[p]; !ok {
+				sourceOf[p] = sourceRoot(source)
+				paths = append(paths, p)
+			}
+		}
+	}
+	for _, p := range uniqueFiles(paths) {
+		files = append(files, ImportFile{Path: p, Source: sourceOf[p]})
+	}
+	return files, nil
+}
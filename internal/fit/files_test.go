@@ -0,0 +1,254 @@
+package fit
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// Creates files (incl. directories) relative to given root
+func createFiles(t *testing.T, root string, files ...string) {
+	t.Helper()
+	for _, f := range files {
+		path := filepath.Join(root, f)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte{}, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func relPaths(t *testing.T, root string, paths []string) []string {
+	t.Helper()
+	var rel []string
+	for _, p := range paths {
+		r, err := filepath.Rel(root, p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rel = append(rel, filepath.ToSlash(r))
+	}
+	slices.Sort(rel)
+	return rel
+}
+
+func TestGetFitFilePaths(t *testing.T) {
+	root := t.TempDir()
+	createFiles(t, root,
+		"a.fit",
+		"notes.txt",
+		"2025/06/b.fit",
+		"2025/06/c.fit",
+		"2025/07/d.fit",
+		"2025/backup/e.fit",
+	)
+	// symlink to a parent directory (cycle) and to a sibling directory (duplicates)
+	if err := os.Symlink(root, filepath.Join(root, "2025", "loop")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(root, "2025", "06"), filepath.Join(root, "june")); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		path     string
+		opts     ImportOptions
+		expected []string
+	}{
+		{
+			name:     "top level only",
+			path:     root,
+			opts:     ImportOptions{},
+			expected: []string{"a.fit"},
+		},
+		{
+			name: "recursive (follows symlinks once, removes duplicates)",
+			path: root,
+			opts: ImportOptions{Recursive: true},
+			expected: []string{
+				"2025/06/b.fit",
+				"2025/06/c.fit",
+				"2025/07/d.fit",
+				"2025/backup/e.fit",
+				"a.fit",
+			},
+		},
+		{
+			name: "recursive with excluded directory name",
+			path: root,
+			opts: ImportOptions{Recursive: true, Exclude: []string{"backup"}},
+			expected: []string{
+				"2025/06/b.fit",
+				"2025/06/c.fit",
+				"2025/07/d.fit",
+				"a.fit",
+			},
+		},
+		{
+			name: "recursive with excluded path pattern",
+			path: root,
+			opts: ImportOptions{Recursive: true, Exclude: []string{filepath.Join(root, "**", "0?", "*")}},
+			expected: []string{
+				"2025/backup/e.fit",
+				"a.fit",
+			},
+		},
+		{
+			name:     "glob",
+			path:     filepath.Join(root, "2025", "06", "*.fit"),
+			opts:     ImportOptions{Exclude: []string{"c.*"}},
+			expected: []string{"2025/06/b.fit"},
+		},
+		{
+			name: "glob with **",
+			path: filepath.Join(root, "2025", "**", "*.fit"),
+			opts: ImportOptions{},
+			expected: []string{
+				"2025/06/b.fit",
+				"2025/06/c.fit",
+				"2025/07/d.fit",
+				"2025/backup/e.fit",
+				// root is reachable via symlink
+				"2025/loop/a.fit",
+			},
+		},
+		{
+			name:     "single file",
+			path:     filepath.Join(root, "a.fit"),
+			opts:     ImportOptions{},
+			expected: []string{"a.fit"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paths, err := GetFitFilePaths(tt.path, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			result := relPaths(t, root, paths)
+			if !slices.Equal(result, tt.expected) {
+				t.Errorf("Expected: %v, Got: %v", tt.expected, result)
+			}
+		})
+	}
+
+	t.Run("no FIT files", func(t *testing.T) {
+		if _, err := GetFitFilePaths(filepath.Join(root, "*.txt"), ImportOptions{}); err == nil {
+			t.Error("Expected error")
+		}
+	})
+}
+
+func TestGetImportFiles(t *testing.T) {
+	root := t.TempDir()
+	createFiles(t, root,
+		"rides/a.fit",
+		"rides/b.fit",
+		"runs/c.fit",
+	)
+
+	files, err := GetImportFiles([]string{
+		filepath.Join(root, "rides"),
+		filepath.Join(root, "runs", "*.fit"),
+		// duplicate
+		filepath.Join(root, "rides", "a.fit"),
+	}, ImportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []ImportFile{
+		{Path: filepath.Join(root, "rides", "a.fit"), Source: filepath.Join(root, "rides")},
+		{Path: filepath.Join(root, "rides", "b.fit"), Source: filepath.Join(root, "rides")},
+		{Path: filepath.Join(root, "runs", "c.fit"), Source: filepath.Join(root, "runs")},
+	}
+	if !slices.Equal(files, expected) {
+		t.Errorf("Expected: %v, Got: %v", expected, files)
+	}
+
+	if _, err := GetImportFiles([]string{filepath.Join(root, "missing")}, ImportOptions{}); err == nil {
+		t.Error("Expected error for a source without FIT files")
+	}
+}
+
+func TestGetFitFilePathsRelativeGlob(t *testing.T) {
+	root := t.TempDir()
+	createFiles(t, root,
+		"2025/06/b.fit",
+		"2025/06/c.fit",
+		"2025/07/d.fit",
+	)
+
+	tests := []struct {
+		name     string
+		dir      string
+		path     string
+		expected []string
+	}{
+		{"./ prefix", filepath.Join(root, "2025"), filepath.FromSlash("./06/**/*.fit"), []string{"06/b.fit", "06/c.fit"}},
+		{"../ prefix", filepath.Join(root, "2025", "07"), filepath.FromSlash("../06/**/*.fit"), []string{"../06/b.fit", "../06/c.fit"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Chdir(tt.dir)
+			paths, err := GetFitFilePaths(tt.path, ImportOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var result []string
+			for _, p := range paths {
+				result = append(result, filepath.ToSlash(p))
+			}
+			slices.Sort(result)
+			if !slices.Equal(result, tt.expected) {
+				t.Errorf("Expected: %v, Got: %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+// Character classes are matched like `filepath.Match`
+func TestGlobToRegexpClasses(t *testing.T) {
+	tests := []struct {
+		pattern string
+		names   []string
+	}{
+		{"[a-c].fit", []string{"a.fit", "b.fit", "d.fit", "-.fit"}},
+		{"[^a].fit", []string{"a.fit", "b.fit"}},
+		{`[\]].fit`, []string{"].fit", `\.fit`}},
+		{`[a\-c].fit`, []string{"a.fit", "b.fit", "-.fit", "c.fit"}},
+		{"[[:alpha:]].fit", []string{"a.fit", "[.fit", "b.fit", ":].fit", "[].fit"}},
+		{"[.].fit", []string{"..fit", "a.fit"}},
+	}
+	for _, tt := range tests {
+		re, err := globToRegexp(tt.pattern)
+		if err != nil {
+			t.Errorf("%s: %v", tt.pattern, err)
+			continue
+		}
+		for _, name := range tt.names {
+			expected, err := filepath.Match(tt.pattern, name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := re.MatchString(name); got != expected {
+				t.Errorf("%s (%s): Expected match: %v, Got: %v", tt.pattern, name, expected, got)
+			}
+		}
+	}
+
+	// `!` negates a class like `^` (not supported by `filepath.Match`)
+	re, err := globToRegexp("[!a].fit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if re.MatchString("a.fit") || !re.MatchString("b.fit") {
+		t.Errorf("Expected [!a] to match all but a")
+	}
+}
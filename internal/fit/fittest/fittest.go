@@ -0,0 +1,77 @@
+// Package fittest provides FIT activities and files for tests,
+// e.g. of the parser and of headless commands.
+package fittest
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/muktihari/fit/encoder"
+	"github.com/muktihari/fit/profile/filedef"
+	"github.com/muktihari/fit/profile/mesgdef"
+	"github.com/muktihari/fit/profile/typedef"
+)
+
+// Start time of activities by `Activity`
+var StartTime = time.Date(2025, 6, 1, 8, 0, 0, 0, time.UTC)
+
+// Ride of `noRecords` records (one per second, 5m each) starting at `StartTime`
+func Activity(noRecords int) *filedef.Activity {
+	return ActivityAt(StartTime, typedef.SportCycling, noRecords)
+}
+
+// Activity of `noRecords` records (one per second, 5m each) with one lap and one session
+func ActivityAt(start time.Time, sport typedef.Sport, noRecords int) *filedef.Activity {
+	act := filedef.NewActivity()
+	act.FileId = *mesgdef.NewFileId(nil).
+		SetType(typedef.FileActivity).
+		SetManufacturer(typedef.ManufacturerGarmin).
+		SetSerialNumber(1234).
+		SetTimeCreated(start)
+	for i := range noRecords {
+		act.Records = append(act.Records, mesgdef.NewRecord(nil).
+			SetTimestamp(start.Add(time.Duration(i)*time.Second)).
+			SetDistance(uint32(i*500)).
+			SetSpeed(5000).
+			SetHeartRate(uint8(120+i)))
+	}
+	finish := start.Add(time.Duration(noRecords-1) * time.Second)
+	elapsed := uint32((noRecords - 1) * 1000)
+	distance := uint32((noRecords - 1) * 500)
+	act.Laps = append(act.Laps, mesgdef.NewLap(nil).
+		SetTimestamp(finish).
+		SetStartTime(start).
+		SetTotalElapsedTime(elapsed).
+		SetTotalTimerTime(elapsed).
+		SetTotalDistance(distance))
+	act.Sessions = append(act.Sessions, mesgdef.NewSession(nil).
+		SetTimestamp(finish).
+		SetStartTime(start).
+		SetSport(sport).
+		SetTotalElapsedTime(elapsed).
+		SetTotalTimerTime(elapsed).
+		SetTotalDistance(distance))
+	act.Activity = mesgdef.NewActivity(nil).
+		SetTimestamp(finish).
+		SetNumSessions(1).
+		SetType(typedef.ActivityManual)
+	return act
+}
+
+// Writes activities into one FIT file (chained FIT files if there are more activities)
+func WriteFile(t testing.TB, path string, acts ...*filedef.Activity) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	enc := encoder.New(f)
+	for _, act := range acts {
+		fit := act.ToFIT(nil)
+		if err := enc.Encode(&fit); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
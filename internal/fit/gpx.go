@@ -0,0 +1,123 @@
+package fit
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/sectore/fit-activities-tui/internal/common"
+)
+
+// GPX 1.0 / 1.1 with Garmin `TrackPointExtension` (heartrate, cadence, temperature).
+// Note: Elements are matched by local names to support all namespace versions.
+type gpxFile struct {
+	Tracks []struct {
+		Type     string `xml:"type"`
+		Segments []struct {
+			Points []gpxPoint `xml:"trkpt"`
+		} `xml:"trkseg"`
+	} `xml:"trk"`
+}
+
+type gpxPoint struct {
+	Lat        float64  `xml:"lat,attr"`
+	Lon        float64  `xml:"lon,attr"`
+	Ele        *float64 `xml:"ele"`
+	Time       string   `xml:"time"`
+	Extensions struct {
+		Power               *uint16 `xml:"power"`
+		TrackPointExtension struct {
+			Temperature *float64 `xml:"atemp"`
+			Heartrate   *uint8   `xml:"hr"`
+			Cadence     *uint8   `xml:"cad"`
+		} `xml:"TrackPointExtension"`
+	} `xml:"extensions"`
+}
+
+// Name of a sport (as defined by the FIT profile) of sport names used by GPX or TCX files
+func parseSportName(name string) string {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "cycling", "biking", "ride", "road_biking", "mountain_biking":
+		return common.SportCycling
+	case "running", "run", "trail_running":
+		return common.SportRunning
+	case "walking", "walk":
+		return common.SportWalking
+	case "hiking", "hike":
+		return common.SportHiking
+	case "swimming", "swim":
+		return common.SportSwimming
+	default:
+		return common.SportGeneric
+	}
+}
+
+func parseXMLTime(value string) *common.Time {
+	t, err := time.Parse(time.RFC3339, strings.TrimSpace(value))
+	if err != nil {
+		return nil
+	}
+	return common.Ptr(common.NewTime(t.Local()))
+}
+
+func parseGPX(r io.Reader, file string) (*common.ActivityData, error) {
+	var gpx gpxFile
+	if err := xml.NewDecoder(r).Decode(&gpx); err != nil {
+		return nil, fmt.Errorf("invalid GPX (file %s): %v", file, err)
+	}
+
+	var records []common.RecordData
+	sport := common.NewSport(common.SportGeneric, "")
+	for index, trk := range gpx.Tracks {
+		name := parseSportName(trk.Type)
+		if index == 0 {
+			sport = common.NewSport(name, "")
+		} else if sport.Name != name {
+			sport = common.NewSport(common.SportMultisport, "")
+		}
+
+		for _, seg := range trk.Segments {
+			for _, p := range seg.Points {
+				record := common.RecordData{
+					Time:     parseXMLTime(p.Time),
+					Position: common.Ptr(common.NewPosition(p.Lat, p.Lon)),
+				}
+				if p.Ele != nil {
+					record.Altitude = common.Ptr(common.NewAltitude(*p.Ele))
+				}
+				ext := p.Extensions.TrackPointExtension
+				if ext.Temperature != nil {
+					record.Temperature = common.Ptr(common.NewTemperature(int8(math.Round(*ext.Temperature))))
+				}
+				if ext.Heartrate != nil {
+					record.Heartrate = common.Ptr(common.NewHeartrate(*ext.Heartrate))
+				}
+				if ext.Cadence != nil {
+					record.Cadence = common.Ptr(common.NewCadence(*ext.Cadence))
+				}
+				if p.Extensions.Power != nil {
+					record.Power = common.Ptr(common.NewPower(*p.Extensions.Power))
+				}
+				records = append(records, record)
+			}
+		}
+	}
+
+	if len(records) <= 0 {
+		return nil, fmt.Errorf("no Records found (file %s)", file)
+	}
+
+	// GPX doesn't include distances or speed
+	common.AddDistances(records)
+	common.AddSpeeds(records)
+
+	data := common.SummarizeRecords(records)
+	data.NoSessions = uint32(max(len(gpx.Tracks), 1))
+	data.Sport = sport
+	data.TotalsByRecords = true
+
+	return &data, nil
+}
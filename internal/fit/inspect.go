@@ -0,0 +1,321 @@
+package fit
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/muktihari/fit/decoder"
+	"github.com/muktihari/fit/kit/datetime"
+	"github.com/muktihari/fit/kit/scaleoffset"
+	"github.com/muktihari/fit/profile"
+	"github.com/muktihari/fit/profile/factory"
+	"github.com/muktihari/fit/profile/mesgdef"
+	"github.com/muktihari/fit/profile/typedef"
+	"github.com/muktihari/fit/proto"
+)
+
+// Low level details of a FIT file to debug files, which can't be parsed (see `Inspect`)
+
+type HeaderInfo struct {
+	Size            byte   `json:"size"`
+	ProtocolVersion string `json:"protocol_version"`
+	ProfileVersion  string `json:"profile_version"`
+	DataSize        uint32 `json:"data_size"`
+	DataType        string `json:"data_type"`
+	CRC             uint16 `json:"crc"`
+}
+
+type MessageCount struct {
+	Message string `json:"message"`
+	Num     uint16 `json:"num"`
+	Count   int    `json:"count"`
+}
+
+type DeveloperDataInfo struct {
+	Index              uint8  `json:"index"`
+	DeveloperId        string `json:"developer_id"`
+	ApplicationId      string `json:"application_id"`
+	ApplicationVersion uint32 `json:"application_version"`
+	Manufacturer       string `json:"manufacturer"`
+}
+
+type FieldInfo struct {
+	Num      byte   `json:"num"`
+	Name     string `json:"name"`
+	Size     byte   `json:"size"`
+	BaseType string `json:"base_type"`
+}
+
+type DeveloperFieldInfo struct {
+	Num                byte   `json:"num"`
+	DeveloperDataIndex byte   `json:"developer_data_index"`
+	Size               byte   `json:"size"`
+	Name               string `json:"name"`
+}
+
+// Definition of a message. Equal definitions are listed once only.
+type DefinitionInfo struct {
+	Message         string               `json:"message"`
+	Num             uint16               `json:"num"`
+	LocalNum        byte                 `json:"local_num"`
+	BigEndian       bool                 `json:"big_endian"`
+	Fields          []FieldInfo          `json:"fields"`
+	DeveloperFields []DeveloperFieldInfo `json:"developer_fields,omitempty"`
+}
+
+// Details of a single FIT file. Chained FIT files contain more than one.
+type Inspection struct {
+	Header        HeaderInfo          `json:"header"`
+	Messages      []MessageCount      `json:"messages"`
+	DeveloperData []DeveloperDataInfo `json:"developer_data"`
+	Definitions   []DefinitionInfo    `json:"definitions"`
+	// Error of decoding the file. All values above are collected until the error occurred.
+	Error string `json:"error,omitempty"`
+}
+
+func formatVersion(major, minor uint16) string {
+	return fmt.Sprintf("%d.%d", major, minor)
+}
+
+// Name of a message. Manufacturer specific messages are named by their number.
+func mesgName(num typedef.MesgNum) string {
+	name := num.String()
+	if strings.HasPrefix(name, "MesgNumInvalid") {
+		return fmt.Sprintf("unknown_%d", num)
+	}
+	return name
+}
+
+func fieldName(num typedef.MesgNum, fieldNum byte) string {
+	name := factory.CreateField(num, fieldNum).Name
+	if name == "" || name == factory.NameUnknown {
+		return fmt.Sprintf("unknown_%d", fieldNum)
+	}
+	return name
+}
+
+// Name of a developer field defined by a `field_description` message
+func developerFieldName(descs []*mesgdef.FieldDescription, index, num byte) string {
+	for _, d := range descs {
+		if d.DeveloperDataIndex == index && d.FieldDefinitionNumber == num && len(d.FieldName) > 0 {
+			return d.FieldName[0]
+		}
+	}
+	return fmt.Sprintf("unknown_%d", num)
+}
+
+type inspector struct {
+	current     *Inspection
+	counts      map[typedef.MesgNum]int
+	definitions map[string]bool
+	fieldDescs  []*mesgdef.FieldDescription
+}
+
+func (in *inspector) reset(insp *Inspection) {
+	in.current = insp
+	in.counts = make(map[typedef.MesgNum]int)
+	in.definitions = make(map[string]bool)
+	in.fieldDescs = nil
+}
+
+func (in *inspector) OnMesgDef(def proto.MessageDefinition) {
+	info := DefinitionInfo{
+		Message:   mesgName(def.MesgNum),
+		Num:       uint16(def.MesgNum),
+		LocalNum:  def.Header & proto.LocalMesgNumMask,
+		BigEndian: def.Architecture == 1,
+	}
+	for _, fd := range def.FieldDefinitions {
+		info.Fields = append(info.Fields, FieldInfo{
+			Num:      fd.Num,
+			Name:     fieldName(def.MesgNum, fd.Num),
+			Size:     fd.Size,
+			BaseType: fd.BaseType.String(),
+		})
+	}
+	for _, dfd := range def.DeveloperFieldDefinitions {
+		info.DeveloperFields = append(info.DeveloperFields, DeveloperFieldInfo{
+			Num:                dfd.Num,
+			DeveloperDataIndex: dfd.DeveloperDataIndex,
+			Size:               dfd.Size,
+			Name:               developerFieldName(in.fieldDescs, dfd.DeveloperDataIndex, dfd.Num),
+		})
+	}
+	// local message numbers are re-defined often, list equal definitions once only
+	key := fmt.Sprintf("%+v", info)
+	if in.definitions[key] {
+		return
+	}
+	in.definitions[key] = true
+	in.current.Definitions = append(in.current.Definitions, info)
+}
+
+func (in *inspector) OnMesg(mesg proto.Message) {
+	in.counts[mesg.Num] += 1
+	switch mesg.Num {
+	case typedef.MesgNumDeveloperDataId:
+		d := mesgdef.NewDeveloperDataId(&mesg)
+		in.current.DeveloperData = append(in.current.DeveloperData, DeveloperDataInfo{
+			Index:              d.DeveloperDataIndex,
+			DeveloperId:        hex.EncodeToString(d.DeveloperId),
+			ApplicationId:      hex.EncodeToString(d.ApplicationId),
+			ApplicationVersion: d.ApplicationVersion,
+			Manufacturer:       d.ManufacturerId.String(),
+		})
+	case typedef.MesgNumFieldDescription:
+		in.fieldDescs = append(in.fieldDescs, mesgdef.NewFieldDescription(&mesg))
+	}
+}
+
+func (in *inspector) finish() {
+	for num, count := range in.counts {
+		in.current.Messages = append(in.current.Messages, MessageCount{
+			Message: mesgName(num),
+			Num:     uint16(num),
+			Count:   count,
+		})
+	}
+	slices.SortFunc(in.current.Messages, func(a, b MessageCount) int {
+		return int(a.Num) - int(b.Num)
+	})
+}
+
+// Inspects a FIT file: header, counts of messages, developer data and definitions of messages.
+// Returns one `Inspection` of each FIT file of a chained FIT file.
+// Errors of decoding are part of an `Inspection` to get details of broken files, too.
+func Inspect(path string) ([]Inspection, error) {
+	f, err := Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	in := &inspector{}
+	dec := decoder.New(f,
+		decoder.WithMesgListener(in),
+		decoder.WithMesgDefListener(in),
+		decoder.WithBroadcastOnly(),
+	)
+
+	var inspections []Inspection
+	for dec.Next() {
+		insp := Inspection{}
+		in.reset(&insp)
+		header, err := dec.PeekFileHeader()
+		if err == nil {
+			insp.Header = HeaderInfo{
+				Size:            header.Size,
+				ProtocolVersion: formatVersion(uint16(header.ProtocolVersion.Major()), uint16(header.ProtocolVersion.Minor())),
+				ProfileVersion:  formatVersion(header.ProfileVersion/1000, header.ProfileVersion%1000),
+				DataSize:        header.DataSize,
+				DataType:        header.DataType,
+				CRC:             header.CRC,
+			}
+			_, err = dec.Decode()
+		}
+		in.finish()
+		if err != nil {
+			insp.Error = err.Error()
+			inspections = append(inspections, insp)
+			// decoding of following (chained) files is not possible
+			break
+		}
+		inspections = append(inspections, insp)
+	}
+
+	if len(inspections) == 0 {
+		return nil, fmt.Errorf("no FIT data found (file %s)", path)
+	}
+	return inspections, nil
+}
+
+// Value of a field: scaled and with times as RFC3339 strings
+func fieldValue(field proto.Field) any {
+	if field.Type == profile.DateTime {
+		if v, ok := field.Value.Any().(uint32); ok {
+			return datetime.ToTime(v).Format(time.RFC3339)
+		}
+	}
+	return scaleoffset.ApplyAny(field.Value.Any(), field.Scale, field.Offset)
+}
+
+// Message as a line of JSON (see `DumpMessages`)
+type MessageDump struct {
+	// Index of the FIT file of a chained FIT file
+	File            int            `json:"file"`
+	Message         string         `json:"message"`
+	Num             uint16         `json:"num"`
+	Fields          map[string]any `json:"fields"`
+	DeveloperFields map[string]any `json:"developer_fields,omitempty"`
+}
+
+type dumper struct {
+	enc        *json.Encoder
+	names      []string
+	file       int
+	fieldDescs []*mesgdef.FieldDescription
+	err        error
+}
+
+func (d *dumper) OnMesg(mesg proto.Message) {
+	if mesg.Num == typedef.MesgNumFieldDescription {
+		d.fieldDescs = append(d.fieldDescs, mesgdef.NewFieldDescription(&mesg))
+	}
+	name := mesgName(mesg.Num)
+	if d.err != nil || (len(d.names) > 0 && !slices.Contains(d.names, name)) {
+		return
+	}
+	dump := MessageDump{
+		File:    d.file,
+		Message: name,
+		Num:     uint16(mesg.Num),
+		Fields:  make(map[string]any, len(mesg.Fields)),
+	}
+	for _, field := range mesg.Fields {
+		name := field.Name
+		if name == "" || name == factory.NameUnknown {
+			name = fmt.Sprintf("unknown_%d", field.Num)
+		}
+		dump.Fields[name] = fieldValue(field)
+	}
+	for _, field := range mesg.DeveloperFields {
+		if dump.DeveloperFields == nil {
+			dump.DeveloperFields = make(map[string]any, len(mesg.DeveloperFields))
+		}
+		name := developerFieldName(d.fieldDescs, field.DeveloperDataIndex, field.Num)
+		dump.DeveloperFields[name] = field.Value.Any()
+	}
+	d.err = d.enc.Encode(dump)
+}
+
+// Writes all messages of a FIT file as JSON lines (see `MessageDump`).
+// Messages can be filtered by names (e.g. `record`, `session`), all messages are written if empty.
+func DumpMessages(w io.Writer, path string, names []string) error {
+	f, err := Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	d := &dumper{enc: json.NewEncoder(w), names: names}
+	dec := decoder.New(f,
+		decoder.WithMesgListener(d),
+		decoder.WithBroadcastOnly(),
+	)
+	for dec.Next() {
+		if _, err := dec.Decode(); err != nil {
+			return err
+		}
+		if d.err != nil {
+			return d.err
+		}
+		d.file += 1
+		d.fieldDescs = nil
+	}
+	return d.err
+}
@@ -0,0 +1,109 @@
+package fit
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sectore/fit-activities-tui/internal/fit/fittest"
+)
+
+func TestInspect(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "chained.fit")
+	fittest.WriteFile(t, path, fittest.Activity(3), fittest.Activity(5))
+
+	inspections, err := Inspect(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(inspections) != 2 {
+		t.Fatalf("Expected 2 inspections, Got: %d", len(inspections))
+	}
+
+	counts := make(map[string]int)
+	for _, m := range inspections[1].Messages {
+		counts[m.Message] = m.Count
+	}
+	expected := map[string]int{"file_id": 1, "record": 5, "lap": 1, "session": 1, "activity": 1}
+	for name, count := range expected {
+		if counts[name] != count {
+			t.Errorf("Expected %d %s messages, Got: %d", count, name, counts[name])
+		}
+	}
+	if inspections[0].Header.DataType != ".FIT" {
+		t.Errorf("Expected data type: .FIT, Got: %s", inspections[0].Header.DataType)
+	}
+	if inspections[0].Error != "" {
+		t.Errorf("Expected no error, Got: %s", inspections[0].Error)
+	}
+	hasRecordDef := false
+	for _, def := range inspections[0].Definitions {
+		if def.Message == "record" {
+			hasRecordDef = true
+		}
+	}
+	if !hasRecordDef {
+		t.Errorf("Expected definition of record messages")
+	}
+}
+
+func TestInspectBrokenFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "broken.fit")
+	fittest.WriteFile(t, path, fittest.Activity(3))
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// drop CRC and the last message
+	if err := os.WriteFile(path, content[:len(content)-10], 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	inspections, err := Inspect(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(inspections) != 1 || inspections[0].Error == "" {
+		t.Fatalf("Expected inspection with an error, Got: %+v", inspections)
+	}
+	if len(inspections[0].Messages) == 0 {
+		t.Errorf("Expected messages decoded before the error")
+	}
+}
+
+func TestDumpMessages(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "ride.fit")
+	fittest.WriteFile(t, path, fittest.Activity(3))
+
+	var buf bytes.Buffer
+	if err := DumpMessages(&buf, path, []string{"record"}); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 lines, Got: %d", len(lines))
+	}
+	var dump MessageDump
+	if err := json.Unmarshal([]byte(lines[2]), &dump); err != nil {
+		t.Fatal(err)
+	}
+	if dump.Message != "record" {
+		t.Errorf("Expected message: record, Got: %s", dump.Message)
+	}
+	// 1000 (scaled by 100) -> 10m
+	if dump.Fields["distance"] != 10.0 {
+		t.Errorf("Expected distance: 10, Got: %v", dump.Fields["distance"])
+	}
+	if dump.Fields["timestamp"] != "2025-06-01T08:00:02Z" {
+		t.Errorf("Expected timestamp: 2025-06-01T08:00:02Z, Got: %v", dump.Fields["timestamp"])
+	}
+	if dump.Fields["heart_rate"] != 122.0 {
+		t.Errorf("Expected heart rate: 122, Got: %v", dump.Fields["heart_rate"])
+	}
+}
@@ -3,21 +3,32 @@ package fit
 import (
 	"fmt"
 	"math"
-	"os"
 
 	"github.com/muktihari/fit/decoder"
+	"github.com/muktihari/fit/kit/semicircles"
 	"github.com/muktihari/fit/profile/basetype"
 	"github.com/muktihari/fit/profile/filedef"
+	"github.com/muktihari/fit/profile/mesgdef"
+	"github.com/muktihari/fit/profile/typedef"
 	"github.com/sectore/fit-activities-tui/internal/common"
 )
 
+// Parses an activity file (FIT, GPX or TCX), which might be compressed (e.g. `.fit.gz`)
+// or part of an archive (virtual path, see `Open`)
 func ParseFile(file string) (*common.ActivityData, error) {
-	f, err := os.Open(file)
+	f, err := Open(file)
 	if err != nil {
 		return nil, err
 	}
 	defer f.Close()
 
+	switch fileExt(file) {
+	case gpxExt:
+		return parseGPX(f, file)
+	case tcxExt:
+		return parseTCX(f, file)
+	}
+
 	lis := filedef.NewListener()
 	defer lis.Close()
 
@@ -44,25 +55,103 @@ func ParseFile(file string) (*common.ActivityData, error) {
 		return nil, fmt.Errorf("no Records found (file %s)", file)
 	}
 
-	var records []common.RecordData
-	speedStats := common.SpeedStats{}
-	var speedCount uint
-	var speedSum uint64
+	records := parseRecords(act.Records)
+
+	// stats of all records (min, max, averages)
+	activityData := common.SummarizeRecords(records)
+
+	var totalDistance *common.Distance
+	durationStats := common.DurationStats{}
+	elevationStats := common.ElevationStats{}
+
+	for _, s := range act.Sessions {
+
+		if s.TotalDistance != basetype.Uint32Invalid {
+			value := s.TotalDistance
+			if totalDistance == nil {
+				d := common.NewDistance(value)
+				totalDistance = common.Ptr(d)
+			} else {
+				// update inner `Value`
+				totalDistance.Value += value
+			}
+		}
+
+		// Duration stats calculation
+		if s.TotalElapsedTime != basetype.Uint32Invalid {
+			value := s.TotalElapsedTime
+			if durationStats.Total == nil {
+				d := common.NewDuration(value)
+				durationStats.Total = common.Ptr(d)
+			} else {
+				// update inner `Value`
+				durationStats.Total.Value += value
+			}
+		}
+		if s.TotalTimerTime != basetype.Uint32Invalid {
+			value := s.TotalTimerTime
+			if durationStats.Active == nil {
+				d := common.NewDuration(value)
+				durationStats.Active = common.Ptr(d)
+			} else {
+				// update inner `Value`
+				durationStats.Active.Value += value
+			}
+		}
+
+		// Elevation stats calculation
+		if s.TotalAscent != basetype.Uint16Invalid {
+			value := s.TotalAscent
+			if elevationStats.Ascents == nil {
+				e := common.NewElevation(value)
+				elevationStats.Ascents = common.Ptr(e)
+			} else {
+				// update inner `Value`
+				elevationStats.Ascents.Value += value
+			}
+		}
+
+		if s.TotalDescent != basetype.Uint16Invalid {
+			value := s.TotalDescent
+			if elevationStats.Descents == nil {
+				e := common.NewElevation(value)
+				elevationStats.Descents = common.Ptr(e)
+			} else {
+				// update inner `Value`
+				elevationStats.Descents.Value += value
+			}
+		}
+	}
+
+	// calculate pause
+	if durationStats.Total != nil && durationStats.Active != nil {
+		d := common.NewDuration(durationStats.Total.Value - durationStats.Active.Value)
+		durationStats.Pause = common.Ptr(d)
+	}
+
+	sport := parseSport(act.Sessions)
 
-	temperatureStats := common.TemperatureStats{}
-	var tempSum int64
-	var tempCount uint
+	var laps []common.LapData
+	for _, l := range act.Laps {
+		laps = append(laps, parseLap(l))
+	}
 
-	gpsAccuracyStats := common.GpsAccuracyStats{}
-	var gpsSum, gpsCount uint
+	// totals are reported by the device (sessions) only, totals calculated by records are used for GPX and TCX files
+	activityData.TotalDistance = totalDistance
+	activityData.Duration = durationStats
+	activityData.Elevation = elevationStats
+	activityData.NoSessions = uint32(noSessions)
+	activityData.Laps = laps
+	activityData.Sport = sport
 
-	altitudeStats := common.AltitudeStats{}
-	var altitudeCount uint
+	return &activityData, nil
+}
 
-	heartrateStats := common.HeartrateStats{}
-	var heartrateSum, heartrateCount uint
+// Converts `Record` messages into records. Invalid values are `nil`.
+func parseRecords(recs []*mesgdef.Record) []common.RecordData {
+	var records []common.RecordData
 
-	for _, r := range act.Records {
+	for _, r := range recs {
 		// Use `EnhancedAltitudeScaled` if available, otherwise fallback to `AltitudeScaled`
 		// This ensures compatibility (Garmin vs. Wahoo)
 		var altitudePtr *common.Altitude
@@ -74,44 +163,10 @@ func ParseFile(file string) (*common.ActivityData, error) {
 			altitudePtr = common.Ptr(altitude)
 		}
 
-		if altitudePtr != nil {
-			// `AltitudeStats` calculation
-			// initialize `max`/`min` on first valid `Altitude`
-			if altitudeCount == 0 {
-				altitudeStats.Min = altitudePtr
-				altitudeStats.Max = altitudePtr
-			}
-
-			if altitudePtr.Value < altitudeStats.Min.Value {
-				altitudeStats.Min = altitudePtr
-			}
-			if altitudePtr.Value > altitudeStats.Max.Value {
-				altitudeStats.Max = altitudePtr
-			}
-
-			altitudeCount += 1
-		}
-
 		var temperaturePtr *common.Temperature
 		if r.Temperature != basetype.Sint8Invalid {
 			temperature := common.NewTemperature(r.Temperature)
 			temperaturePtr = common.Ptr(temperature)
-			// `Temperature` stats calculation
-			// initialize min/max on first valid `Temperature`
-			if tempCount == 0 {
-				temperatureStats.Min = temperaturePtr
-				temperatureStats.Max = temperaturePtr
-			}
-			// compare min
-			if temperature.Value < temperatureStats.Min.Value {
-				temperatureStats.Min = temperaturePtr
-			}
-			// compare max
-			if temperature.Value > temperatureStats.Max.Value {
-				temperatureStats.Max = temperaturePtr
-			}
-			tempCount += 1
-			tempSum += int64(temperature.Value)
 		}
 
 		var distancePtr *common.Distance
@@ -131,62 +186,38 @@ func ParseFile(file string) (*common.ActivityData, error) {
 			speedPtr = common.Ptr(speed)
 		}
 
-		if speedPtr != nil {
-			// `SpeedStats` calculation
-			// initialize `max` on first valid `Speed`
-			if speedCount == 0 {
-				speedStats.Max = speedPtr
-			}
-
-			if speedStats.Max.Value < speedPtr.Value {
-				speedStats.Max = speedPtr
-			}
-			speedCount += 1
-			speedSum += uint64(speedPtr.Value)
-		}
-
 		var gpsAccuracyPtr *common.GpsAccuracy
 		if r.GpsAccuracy != basetype.Uint8Invalid {
 			gpsAccuracy := common.NewGpsAccuracy(r.GpsAccuracy)
 			gpsAccuracyPtr = common.Ptr(gpsAccuracy)
-			// `GpsAccuracyStats` calculation
-			// initialize min/max on first valid `GpsAccuracy`
-			if gpsCount == 0 {
-				gpsAccuracyStats.Min = gpsAccuracyPtr
-				gpsAccuracyStats.Max = gpsAccuracyPtr
-			}
-			// compare min
-			if gpsAccuracy.Value < gpsAccuracyStats.Min.Value {
-				gpsAccuracyStats.Min = gpsAccuracyPtr
-			}
-			// compare max
-			if gpsAccuracy.Value > gpsAccuracyStats.Max.Value {
-				gpsAccuracyStats.Max = gpsAccuracyPtr
-			}
-			gpsCount += 1
-			gpsSum += uint(gpsAccuracy.Value)
+		}
+
+		var positionPtr *common.Position
+		// Positions are stored in semicircles, which need to be converted into degrees
+		if r.PositionLat != basetype.Sint32Invalid && r.PositionLong != basetype.Sint32Invalid {
+			position := common.NewPosition(
+				semicircles.ToDegrees(r.PositionLat),
+				semicircles.ToDegrees(r.PositionLong),
+			)
+			positionPtr = common.Ptr(position)
 		}
 
 		var heartratePtr *common.Heartrate
 		if r.HeartRate != basetype.Uint8Invalid {
 			heartrate := common.NewHeartrate(r.HeartRate)
 			heartratePtr = common.Ptr(heartrate)
-			// `HeartrateStats` calculation
-			// initialize min/max on first valid `Heartrate`
-			if heartrateCount == 0 {
-				heartrateStats.Min = heartratePtr
-				heartrateStats.Max = heartratePtr
-			}
-			// compare min
-			if heartrate.Value < heartrateStats.Min.Value {
-				heartrateStats.Min = heartratePtr
-			}
-			// compare max
-			if heartrate.Value > heartrateStats.Max.Value {
-				heartrateStats.Max = heartratePtr
-			}
-			heartrateCount += 1
-			heartrateSum += uint(heartrate.Value)
+		}
+
+		var cadencePtr *common.Cadence
+		if r.Cadence != basetype.Uint8Invalid {
+			cadence := common.NewCadence(r.Cadence)
+			cadencePtr = common.Ptr(cadence)
+		}
+
+		var powerPtr *common.Power
+		if r.Power != basetype.Uint16Invalid {
+			power := common.NewPower(r.Power)
+			powerPtr = common.Ptr(power)
 		}
 
 		var timePtr *common.Time
@@ -202,119 +233,104 @@ func ParseFile(file string) (*common.ActivityData, error) {
 			Temperature: temperaturePtr,
 			Altitude:    altitudePtr,
 			GpsAccuracy: gpsAccuracyPtr,
+			Position:    positionPtr,
 			Heartrate:   heartratePtr,
+			Power:       powerPtr,
+			Cadence:     cadencePtr,
 		}
 		records = append(records, record)
-
 	}
+	return records
+}
 
-	// Calculate `Speed` average
-	if speedCount > 0 {
-		speed := common.NewSpeed(float32(speedSum) / float32(speedCount))
-		speedStats.Avg = common.Ptr(speed)
-	}
+// Sport of the first `Session`.
+// Sessions of different sports (e.g. triathlon) are treated as multisport.
+func parseSport(sessions []*mesgdef.Session) common.Sport {
+	sport := common.NewSport(common.SportGeneric, "")
+	for index, s := range sessions {
+		name := common.SportGeneric
+		if s.Sport != typedef.SportInvalid {
+			name = s.Sport.String()
+		}
+		subName := ""
+		if s.SubSport != typedef.SubSportInvalid {
+			subName = s.SubSport.String()
+		}
 
-	// Calculate `Temperature` average
-	// Use `math.Round` for symmetric handling: truncation has sign-dependent bias
-	// (positive: 16.5°C → 16°C; negative: -0.5°C → 0°C truncates toward zero)
-	if tempCount > 0 {
-		temperature := common.NewTemperature(int8(math.Round(float64(tempSum) / float64(tempCount))))
-		temperatureStats.Avg = common.Ptr(temperature)
+		if index == 0 {
+			sport = common.NewSport(name, subName)
+		} else if sport.Name != name {
+			return common.NewSport(common.SportMultisport, "")
+		}
 	}
+	return sport
+}
 
-	// Calculate `GpsAccuracy` average
-	if gpsCount > 0 {
-		gpsAccuracy := common.NewGpsAccuracy(uint8(float32(gpsSum) / float32(gpsCount)))
-		gpsAccuracyStats.Avg = common.Ptr(gpsAccuracy)
-	}
+func parseLap(l *mesgdef.Lap) common.LapData {
+	lap := common.LapData{}
 
-	// Calculate `Heartrate` average
-	if heartrateCount > 0 {
-		heartrate := common.NewHeartrate(uint8(float32(heartrateSum) / float32(heartrateCount)))
-		heartrateStats.Avg = common.Ptr(heartrate)
+	if !l.StartTime.IsZero() {
+		t := common.NewTime(l.StartTime.Local())
+		lap.StartTime = common.Ptr(t)
+	}
+	// `Timestamp` of a lap is its end time
+	if !l.Timestamp.IsZero() {
+		t := common.NewTime(l.Timestamp.Local())
+		lap.FinishTime = common.Ptr(t)
 	}
 
-	var totalDistance *common.Distance
-	durationStats := common.DurationStats{}
-	elevationStats := common.ElevationStats{}
-
-	for _, s := range act.Sessions {
-
-		if s.TotalDistance != basetype.Uint32Invalid {
-			value := s.TotalDistance
-			if totalDistance == nil {
-				d := common.NewDistance(value)
-				totalDistance = common.Ptr(d)
-			} else {
-				// update inner `Value`
-				totalDistance.Value += value
-			}
-		}
-
-		// Duration stats calculation
-		if s.TotalElapsedTime != basetype.Uint32Invalid {
-			value := s.TotalElapsedTime
-			if durationStats.Total == nil {
-				d := common.NewDuration(value)
-				durationStats.Total = common.Ptr(d)
-			} else {
-				// update inner `Value`
-				durationStats.Total.Value += value
-			}
-		}
-		if s.TotalTimerTime != basetype.Uint32Invalid {
-			value := s.TotalTimerTime
-			if durationStats.Active == nil {
-				d := common.NewDuration(value)
-				durationStats.Active = common.Ptr(d)
-			} else {
-				// update inner `Value`
-				durationStats.Active.Value += value
-			}
-		}
+	if l.TotalElapsedTime != basetype.Uint32Invalid {
+		d := common.NewDuration(l.TotalElapsedTime)
+		lap.Duration.Total = common.Ptr(d)
+	}
+	if l.TotalTimerTime != basetype.Uint32Invalid {
+		d := common.NewDuration(l.TotalTimerTime)
+		lap.Duration.Active = common.Ptr(d)
+	}
+	if lap.Duration.Total != nil && lap.Duration.Active != nil &&
+		lap.Duration.Total.Value >= lap.Duration.Active.Value {
+		d := common.NewDuration(lap.Duration.Total.Value - lap.Duration.Active.Value)
+		lap.Duration.Pause = common.Ptr(d)
+	}
 
-		// Elevation stats calculation
-		if s.TotalAscent != basetype.Uint16Invalid {
-			value := s.TotalAscent
-			if elevationStats.Ascents == nil {
-				e := common.NewElevation(value)
-				elevationStats.Ascents = common.Ptr(e)
-			} else {
-				// update inner `Value`
-				elevationStats.Ascents.Value += value
-			}
-		}
+	if l.TotalDistance != basetype.Uint32Invalid {
+		d := common.NewDistance(l.TotalDistance)
+		lap.Distance = common.Ptr(d)
+	}
 
-		if s.TotalDescent != basetype.Uint16Invalid {
-			value := s.TotalDescent
-			if elevationStats.Descents == nil {
-				e := common.NewElevation(value)
-				elevationStats.Descents = common.Ptr(e)
-			} else {
-				// update inner `Value`
-				elevationStats.Descents.Value += value
-			}
-		}
+	// Use enhanced speed values if available, otherwise fallback (see `Record` parsing)
+	if l.EnhancedAvgSpeed != basetype.Uint32Invalid {
+		speed := common.NewSpeed(float32(l.EnhancedAvgSpeed))
+		lap.Speed.Avg = common.Ptr(speed)
+	} else if l.AvgSpeed != basetype.Uint16Invalid {
+		speed := common.NewSpeed(float32(l.AvgSpeed))
+		lap.Speed.Avg = common.Ptr(speed)
+	}
+	if l.EnhancedMaxSpeed != basetype.Uint32Invalid {
+		speed := common.NewSpeed(float32(l.EnhancedMaxSpeed))
+		lap.Speed.Max = common.Ptr(speed)
+	} else if l.MaxSpeed != basetype.Uint16Invalid {
+		speed := common.NewSpeed(float32(l.MaxSpeed))
+		lap.Speed.Max = common.Ptr(speed)
 	}
 
-	// calculate pause
-	if durationStats.Total != nil && durationStats.Active != nil {
-		d := common.NewDuration(durationStats.Total.Value - durationStats.Active.Value)
-		durationStats.Pause = common.Ptr(d)
+	if l.AvgHeartRate != basetype.Uint8Invalid {
+		hr := common.NewHeartrate(l.AvgHeartRate)
+		lap.Heartrate.Avg = common.Ptr(hr)
+	}
+	if l.MaxHeartRate != basetype.Uint8Invalid {
+		hr := common.NewHeartrate(l.MaxHeartRate)
+		lap.Heartrate.Max = common.Ptr(hr)
 	}
 
-	var activityData = &common.ActivityData{
-		Duration:      durationStats,
-		TotalDistance: totalDistance,
-		Temperature:   temperatureStats,
-		Speed:         speedStats,
-		Elevation:     elevationStats,
-		NoSessions:    uint32(noSessions),
-		Records:       records,
-		GpsAccuracy:   gpsAccuracyStats,
-		Altitude:      altitudeStats,
-		Heartrate:     heartrateStats,
+	if l.TotalAscent != basetype.Uint16Invalid {
+		e := common.NewElevation(l.TotalAscent)
+		lap.Elevation.Ascents = common.Ptr(e)
+	}
+	if l.TotalDescent != basetype.Uint16Invalid {
+		e := common.NewElevation(l.TotalDescent)
+		lap.Elevation.Descents = common.Ptr(e)
 	}
 
-	return activityData, nil
+	return lap
 }
@@ -0,0 +1,392 @@
+package fit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/muktihari/fit/profile/basetype"
+	"github.com/muktihari/fit/profile/mesgdef"
+	"github.com/muktihari/fit/profile/typedef"
+	"github.com/sectore/fit-activities-tui/internal/common"
+	"github.com/sectore/fit-activities-tui/internal/fit/fittest"
+)
+
+func TestParseGPX(t *testing.T) {
+	data, err := ParseFile(filepath.Join("testdata", "ride.gpx"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if data.NoRecords() != 3 {
+		t.Fatalf("Expected 3 records, Got: %d", data.NoRecords())
+	}
+	if data.Sport.Name != "cycling" {
+		t.Errorf("Expected sport: cycling, Got: %s", data.Sport.Name)
+	}
+	// 2 x ~111m
+	if data.TotalDistance == nil || data.TotalDistance.Value < 22200 || data.TotalDistance.Value > 22300 {
+		t.Errorf("Expected distance of ~222m, Got: %v", data.TotalDistance)
+	}
+	if data.Duration.Total == nil || data.Duration.Total.Value != 80_000 {
+		t.Errorf("Expected total duration: 80s, Got: %v", data.Duration.Total)
+	}
+	// gap of 70s is a pause
+	if data.Duration.Active == nil || data.Duration.Active.Value != 10_000 {
+		t.Errorf("Expected active duration: 10s, Got: %v", data.Duration.Active)
+	}
+	if data.Elevation.Ascents == nil || data.Elevation.Ascents.Value != 5 {
+		t.Errorf("Expected ascents: 5m, Got: %v", data.Elevation.Ascents)
+	}
+	if data.Elevation.Descents == nil || data.Elevation.Descents.Value != 4 {
+		t.Errorf("Expected descents: 4m, Got: %v", data.Elevation.Descents)
+	}
+	if data.Heartrate.Avg == nil || data.Heartrate.Avg.Value != 130 {
+		t.Errorf("Expected avg. heartrate: 130, Got: %v", data.Heartrate.Avg)
+	}
+	if data.Temperature.Max == nil || data.Temperature.Max.Value != 20 {
+		t.Errorf("Expected max. temperature: 20, Got: %v", data.Temperature.Max)
+	}
+	if data.Cadence.Avg == nil || data.Cadence.Avg.Value != 85 {
+		t.Errorf("Expected avg. cadence: 85, Got: %v", data.Cadence.Avg)
+	}
+	if data.Power.Max == nil || data.Power.Max.Value != 240 {
+		t.Errorf("Expected max. power: 240, Got: %v", data.Power.Max)
+	}
+	if data.Speed.Max == nil || data.Records[1].Speed == nil {
+		t.Errorf("Expected speed calculated by distances")
+	}
+	if !data.TotalsByRecords {
+		t.Error("Expected totals calculated by records")
+	}
+}
+
+func TestParseTCX(t *testing.T) {
+	data, err := ParseFile(filepath.Join("testdata", "run.tcx"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if data.NoRecords() != 3 {
+		t.Fatalf("Expected 3 records, Got: %d", data.NoRecords())
+	}
+	if data.Sport.Name != "running" {
+		t.Errorf("Expected sport: running, Got: %s", data.Sport.Name)
+	}
+	if data.TotalDistance == nil || data.TotalDistance.Value != 6500 {
+		t.Errorf("Expected distance: 65m, Got: %v", data.TotalDistance)
+	}
+	if data.Duration.Total == nil || data.Duration.Total.Value != 20_000 {
+		t.Errorf("Expected total duration: 20s, Got: %v", data.Duration.Total)
+	}
+	if data.Duration.Active == nil || data.Duration.Active.Value != 18_000 {
+		t.Errorf("Expected active duration: 18s, Got: %v", data.Duration.Active)
+	}
+	if data.Duration.Pause == nil || data.Duration.Pause.Value != 2_000 {
+		t.Errorf("Expected pause: 2s, Got: %v", data.Duration.Pause)
+	}
+	if len(data.Laps) != 2 {
+		t.Fatalf("Expected 2 laps, Got: %d", len(data.Laps))
+	}
+	if lap := data.Laps[0]; lap.Heartrate.Max == nil || lap.Heartrate.Max.Value != 155 ||
+		lap.Speed.Max == nil || lap.Speed.Max.Value != 4000 {
+		t.Errorf("Unexpected first lap: %+v", lap)
+	}
+	if data.Cadence.Max == nil || data.Cadence.Max.Value != 90 {
+		t.Errorf("Expected max. cadence: 90, Got: %v", data.Cadence.Max)
+	}
+	if data.Speed.Max == nil || data.Speed.Max.Value != 4000 {
+		t.Errorf("Expected max. speed: 4m/s, Got: %v", data.Speed.Max)
+	}
+	if !data.HasPositions() {
+		t.Error("Expected positions")
+	}
+	if !data.TotalsByRecords {
+		t.Error("Expected totals calculated by records")
+	}
+}
+
+func TestParseCompressedGPX(t *testing.T) {
+	content, err := os.ReadFile(filepath.Join("testdata", "ride.gpx"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "ride.gpx.gz")
+	if err := os.WriteFile(path, gzipped(t, string(content)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ParseFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.NoRecords() != 3 {
+		t.Errorf("Expected 3 records, Got: %d", data.NoRecords())
+	}
+}
+
+func TestParseInvalidFiles(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"empty.gpx":   `<gpx version="1.1"></gpx>`,
+		"broken.gpx":  `<gpx version="1.1"><trk>`,
+		"empty.tcx":   `<TrainingCenterDatabase></TrainingCenterDatabase>`,
+		"no-laps.tcx": `<TrainingCenterDatabase><Activities><Activity Sport="Biking"></Activity></Activities></TrainingCenterDatabase>`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ParseFile(path); err == nil {
+			t.Errorf("%s: Expected error", name)
+		}
+	}
+}
+
+func TestParseFitWithoutSessionTotals(t *testing.T) {
+	act := fittest.Activity(5)
+	// session without any totals
+	act.Sessions[0] = mesgdef.NewSession(nil).
+		SetTimestamp(act.Sessions[0].Timestamp).
+		SetSport(typedef.SportCycling)
+	path := filepath.Join(t.TempDir(), "no-totals.fit")
+	fittest.WriteFile(t, path, act)
+
+	data, err := ParseFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// no totals calculated by records
+	if data.TotalDistance != nil {
+		t.Errorf("Expected no distance, Got: %v", data.TotalDistance)
+	}
+	if data.Duration.Total != nil || data.Duration.Active != nil {
+		t.Errorf("Expected no duration, Got: %+v", data.Duration)
+	}
+	if data.Elevation.Ascents != nil || data.Elevation.Descents != nil {
+		t.Errorf("Expected no elevation, Got: %+v", data.Elevation)
+	}
+	if data.TotalsByRecords {
+		t.Error("Expected totals of the device")
+	}
+	// stats of records are still available
+	if data.Heartrate.Max == nil || data.Heartrate.Max.Value != 124 {
+		t.Errorf("Expected max. heartrate: 124, Got: %v", data.Heartrate.Max)
+	}
+}
+
+func TestParseFitCadence(t *testing.T) {
+	// `nil` for no cadence
+	cadence := func(value *common.Cadence) *uint8 {
+		if value == nil {
+			return nil
+		}
+		return &value.Value
+	}
+	invalid := basetype.Uint8Invalid
+
+	tests := []struct {
+		name                        string
+		cadences                    []uint8
+		min, max, avg, avgWithZeros *uint8
+	}{
+		{
+			name:         "all pedaling",
+			cadences:     []uint8{80, 90, 100},
+			min:          common.Ptr[uint8](80),
+			max:          common.Ptr[uint8](100),
+			avg:          common.Ptr[uint8](90),
+			avgWithZeros: common.Ptr[uint8](90),
+		},
+		{
+			name:         "coasting",
+			cadences:     []uint8{80, 0, 90, 0, 100},
+			min:          common.Ptr[uint8](0),
+			max:          common.Ptr[uint8](100),
+			avg:          common.Ptr[uint8](90),
+			avgWithZeros: common.Ptr[uint8](54),
+		},
+		{
+			name:         "coasting only",
+			cadences:     []uint8{0, 0},
+			min:          common.Ptr[uint8](0),
+			max:          common.Ptr[uint8](0),
+			avg:          nil,
+			avgWithZeros: common.Ptr[uint8](0),
+		},
+		{
+			name:         "missing values",
+			cadences:     []uint8{invalid, 70, invalid, 80},
+			min:          common.Ptr[uint8](70),
+			max:          common.Ptr[uint8](80),
+			avg:          common.Ptr[uint8](75),
+			avgWithZeros: common.Ptr[uint8](75),
+		},
+		{
+			name:     "no cadence",
+			cadences: []uint8{invalid, invalid},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			act := fittest.Activity(len(tt.cadences))
+			for i, c := range tt.cadences {
+				act.Records[i].SetCadence(c)
+			}
+			path := filepath.Join(t.TempDir(), "cadence.fit")
+			fittest.WriteFile(t, path, act)
+
+			data, err := ParseFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			stats := []struct {
+				name     string
+				expected *uint8
+				got      *uint8
+			}{
+				{"min", tt.min, cadence(data.Cadence.Min)},
+				{"max", tt.max, cadence(data.Cadence.Max)},
+				{"avg", tt.avg, cadence(data.Cadence.Avg)},
+				{"avg incl. zeros", tt.avgWithZeros, cadence(data.Cadence.AvgWithZeros)},
+			}
+			for _, s := range stats {
+				if (s.expected == nil) != (s.got == nil) || (s.expected != nil && *s.expected != *s.got) {
+					t.Errorf("%s - Expected: %v, Got: %v", s.name, formatOptional(s.expected), formatOptional(s.got))
+				}
+			}
+		})
+	}
+}
+
+func formatOptional(value *uint8) string {
+	if value == nil {
+		return "nil"
+	}
+	return fmt.Sprint(*value)
+}
+
+func TestParseLap(t *testing.T) {
+	start := fittest.StartTime
+	finish := fittest.StartTime.Add(10 * time.Minute)
+	localTime := func(value time.Time) *common.Time { return common.Ptr(common.NewTime(value.Local())) }
+
+	tests := []struct {
+		name     string
+		lap      *mesgdef.Lap
+		expected common.LapData
+	}{
+		{
+			name: "all values",
+			lap: mesgdef.NewLap(nil).
+				SetStartTime(start).
+				SetTimestamp(finish).
+				SetTotalElapsedTime(600_000).
+				SetTotalTimerTime(540_000).
+				SetTotalDistance(300_000).
+				SetEnhancedAvgSpeed(5000).
+				SetEnhancedMaxSpeed(9000).
+				SetAvgHeartRate(140).
+				SetMaxHeartRate(170).
+				SetTotalAscent(50).
+				SetTotalDescent(40),
+			expected: common.LapData{
+				StartTime:  localTime(start),
+				FinishTime: localTime(finish),
+				Duration: common.DurationStats{
+					Total:  common.Ptr(common.NewDuration(600_000)),
+					Active: common.Ptr(common.NewDuration(540_000)),
+					Pause:  common.Ptr(common.NewDuration(60_000)),
+				},
+				Distance:  common.Ptr(common.NewDistance(300_000)),
+				Speed:     common.SpeedStats{Avg: common.Ptr(common.NewSpeed(5000)), Max: common.Ptr(common.NewSpeed(9000))},
+				Heartrate: common.HeartrateStats{Avg: common.Ptr(common.NewHeartrate(140)), Max: common.Ptr(common.NewHeartrate(170))},
+				Elevation: common.ElevationStats{Ascents: common.Ptr(common.NewElevation(50)), Descents: common.Ptr(common.NewElevation(40))},
+			},
+		},
+		{
+			name: "speed without enhanced values",
+			lap: mesgdef.NewLap(nil).
+				SetAvgSpeed(4000).
+				SetMaxSpeed(6000),
+			expected: common.LapData{
+				Speed: common.SpeedStats{Avg: common.Ptr(common.NewSpeed(4000)), Max: common.Ptr(common.NewSpeed(6000))},
+			},
+		},
+		{
+			name: "enhanced speed preferred",
+			lap: mesgdef.NewLap(nil).
+				SetAvgSpeed(4000).
+				SetEnhancedAvgSpeed(4100),
+			expected: common.LapData{
+				Speed: common.SpeedStats{Avg: common.Ptr(common.NewSpeed(4100))},
+			},
+		},
+		{
+			name: "no pause if timer time exceeds elapsed time",
+			lap: mesgdef.NewLap(nil).
+				SetTotalElapsedTime(1000).
+				SetTotalTimerTime(2000),
+			expected: common.LapData{
+				Duration: common.DurationStats{
+					Total:  common.Ptr(common.NewDuration(1000)),
+					Active: common.Ptr(common.NewDuration(2000)),
+				},
+			},
+		},
+		{
+			name:     "no values",
+			lap:      mesgdef.NewLap(nil),
+			expected: common.LapData{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseLap(tt.lap)
+			if !reflect.DeepEqual(got, tt.expected) {
+				expected, _ := json.Marshal(tt.expected)
+				actual, _ := json.Marshal(got)
+				t.Errorf("Expected: %s, Got: %s", expected, actual)
+			}
+		})
+	}
+}
+
+func TestParseSport(t *testing.T) {
+	session := func(sport typedef.Sport, subSport typedef.SubSport) *mesgdef.Session {
+		return mesgdef.NewSession(nil).SetSport(sport).SetSubSport(subSport)
+	}
+
+	tests := []struct {
+		name     string
+		sessions []*mesgdef.Session
+		expected common.Sport
+	}{
+		{"no sessions", nil, common.NewSport(common.SportGeneric, "")},
+		{"single sport", []*mesgdef.Session{session(typedef.SportRunning, typedef.SubSportTrail)}, common.NewSport(common.SportRunning, "trail")},
+		{"invalid sport", []*mesgdef.Session{session(typedef.SportInvalid, typedef.SubSportInvalid)}, common.NewSport(common.SportGeneric, "")},
+		{"same sport of all sessions", []*mesgdef.Session{
+			session(typedef.SportCycling, typedef.SubSportRoad),
+			session(typedef.SportCycling, typedef.SubSportGravelCycling),
+		}, common.NewSport(common.SportCycling, "road")},
+		{"multisport (e.g. triathlon)", []*mesgdef.Session{
+			session(typedef.SportSwimming, typedef.SubSportOpenWater),
+			session(typedef.SportCycling, typedef.SubSportRoad),
+			session(typedef.SportRunning, typedef.SubSportStreet),
+		}, common.NewSport(common.SportMultisport, "")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseSport(tt.sessions); got != tt.expected {
+				t.Errorf("Expected: %+v, Got: %+v", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package fit
+
+import (
+	"github.com/muktihari/fit/kit/semicircles"
+	"github.com/muktihari/fit/profile/basetype"
+	"github.com/muktihari/fit/profile/filedef"
+	"github.com/muktihari/fit/profile/mesgdef"
+	"github.com/muktihari/fit/profile/untyped/mesgnum"
+	"github.com/sectore/fit-activities-tui/internal/common"
+)
+
+// Position of semicircles or nil if lat or long are invalid
+func semicirclesPosition(lat, long int32) *common.Position {
+	if lat == basetype.Sint32Invalid || long == basetype.Sint32Invalid {
+		return nil
+	}
+	return common.Ptr(common.NewPosition(semicircles.ToDegrees(lat), semicircles.ToDegrees(long)))
+}
+
+// Checks whether a position (semicircles) is part of any zone
+func inPrivacyZones(zones common.PrivacyZones, lat, long int32) bool {
+	p := semicirclesPosition(lat, long)
+	return p != nil && zones.Contains(*p)
+}
+
+// Removes all positions inside of privacy zones: positions of records
+// (other values of these records are kept), start and end positions of laps and sessions.
+// Bounding boxes of sessions are recalculated by remaining positions.
+// Returns number of records with removed positions.
+func ApplyPrivacyZones(act *filedef.Activity, zones common.PrivacyZones) int {
+	if len(zones) == 0 {
+		return 0
+	}
+
+	removed := 0
+	for _, r := range act.Records {
+		if inPrivacyZones(zones, r.PositionLat, r.PositionLong) {
+			r.PositionLat, r.PositionLong = basetype.Sint32Invalid, basetype.Sint32Invalid
+			removed += 1
+		}
+	}
+
+	for _, l := range act.Laps {
+		if inPrivacyZones(zones, l.StartPositionLat, l.StartPositionLong) {
+			l.StartPositionLat, l.StartPositionLong = basetype.Sint32Invalid, basetype.Sint32Invalid
+		}
+		if inPrivacyZones(zones, l.EndPositionLat, l.EndPositionLong) {
+			l.EndPositionLat, l.EndPositionLong = basetype.Sint32Invalid, basetype.Sint32Invalid
+		}
+	}
+
+	for _, s := range act.Sessions {
+		if inPrivacyZones(zones, s.StartPositionLat, s.StartPositionLong) {
+			s.StartPositionLat, s.StartPositionLong = basetype.Sint32Invalid, basetype.Sint32Invalid
+		}
+		if inPrivacyZones(zones, s.EndPositionLat, s.EndPositionLong) {
+			s.EndPositionLat, s.EndPositionLong = basetype.Sint32Invalid, basetype.Sint32Invalid
+		}
+		applyBoundingBox(s, act.Records)
+	}
+
+	for _, g := range act.GpsMetadatas {
+		if inPrivacyZones(zones, g.PositionLat, g.PositionLong) {
+			g.PositionLat, g.PositionLong = basetype.Sint32Invalid, basetype.Sint32Invalid
+		}
+	}
+
+	return removed
+}
+
+// Sets bounding box of a session by positions of its records
+func applyBoundingBox(s *mesgdef.Session, records []*mesgdef.Record) {
+	s.NecLat, s.NecLong = basetype.Sint32Invalid, basetype.Sint32Invalid
+	s.SwcLat, s.SwcLong = basetype.Sint32Invalid, basetype.Sint32Invalid
+	for _, r := range records {
+		if r.PositionLat == basetype.Sint32Invalid || r.PositionLong == basetype.Sint32Invalid {
+			continue
+		}
+		if (!s.StartTime.IsZero() && r.Timestamp.Before(s.StartTime)) ||
+			(!s.Timestamp.IsZero() && r.Timestamp.After(s.Timestamp)) {
+			continue
+		}
+		if s.NecLat == basetype.Sint32Invalid {
+			s.NecLat, s.SwcLat = r.PositionLat, r.PositionLat
+			s.NecLong, s.SwcLong = r.PositionLong, r.PositionLong
+			continue
+		}
+		s.NecLat, s.SwcLat = max(s.NecLat, r.PositionLat), min(s.SwcLat, r.PositionLat)
+		s.NecLong, s.SwcLong = max(s.NecLong, r.PositionLong), min(s.SwcLong, r.PositionLong)
+	}
+}
+
+// Removes personal data of an activity: serial numbers of all devices and the user profile
+// (e.g. name, weight, age).
+func Anonymize(act *filedef.Activity) {
+	act.FileId.SerialNumber = basetype.Uint32zInvalid
+	var devices []*mesgdef.DeviceInfo
+	for _, d := range act.DeviceInfos {
+		d.SerialNumber = basetype.Uint32zInvalid
+		// messages without any fields can't be encoded
+		if mesg := d.ToMesg(nil); len(mesg.Fields) > 0 {
+			devices = append(devices, d)
+		}
+	}
+	act.DeviceInfos = devices
+	act.UserProfile = nil
+
+	// additional user profiles of other devices (not handled by `filedef.Activity`)
+	mesgs := act.UnrelatedMessages[:0]
+	for _, m := range act.UnrelatedMessages {
+		if m.Num != mesgnum.UserProfile {
+			mesgs = append(mesgs, m)
+		}
+	}
+	act.UnrelatedMessages = mesgs
+}
@@ -0,0 +1,91 @@
+package fit
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/muktihari/fit/profile/basetype"
+	"github.com/muktihari/fit/profile/mesgdef"
+	"github.com/sectore/fit-activities-tui/internal/common"
+	"github.com/sectore/fit-activities-tui/internal/fit/fittest"
+)
+
+func TestApplyPrivacyZones(t *testing.T) {
+	act := fittest.Activity(5)
+	// ~11m between records, starting at the zone
+	for i, r := range act.Records {
+		r.SetPositionLatDegrees(52.52 + float64(i)*0.0001).
+			SetPositionLongDegrees(13.4)
+	}
+	act.Sessions[0].SetStartPositionLatDegrees(52.52).SetStartPositionLongDegrees(13.4)
+	zones := common.PrivacyZones{{Center: common.NewPosition(52.52, 13.4), Radius: 15}}
+
+	if removed := ApplyPrivacyZones(act, zones); removed != 2 {
+		t.Errorf("Expected 2 removed positions, Got: %d", removed)
+	}
+	path := filepath.Join(t.TempDir(), "private.fit")
+	fittest.WriteFile(t, path, act)
+
+	data, err := ParseFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// all records are kept
+	if data.NoRecords() != 5 {
+		t.Fatalf("Expected 5 records, Got: %d", data.NoRecords())
+	}
+	for i, r := range data.Records {
+		if hasPosition := r.Position != nil; hasPosition != (i >= 2) {
+			t.Errorf("record %d: Expected position: %v, Got: %v", i, i >= 2, r.Position)
+		}
+	}
+	s := act.Sessions[0]
+	if s.StartPositionLat != basetype.Sint32Invalid {
+		t.Errorf("Expected no start position of session, Got: %v", s.StartPositionLatDegrees())
+	}
+	// bounding box of remaining positions
+	if lat := s.SwcLatDegrees(); lat < 52.5201 {
+		t.Errorf("Expected bounding box outside of privacy zone, Got: %v", lat)
+	}
+}
+
+func TestAnonymize(t *testing.T) {
+	act := fittest.Activity(3)
+	act.DeviceInfos = append(act.DeviceInfos,
+		mesgdef.NewDeviceInfo(nil).SetTimestamp(fittest.StartTime).SetSerialNumber(5678),
+		// serial number only
+		mesgdef.NewDeviceInfo(nil).SetSerialNumber(9012))
+	act.UserProfile = mesgdef.NewUserProfile(nil).SetFriendlyName("Jane")
+	Anonymize(act)
+
+	path := filepath.Join(t.TempDir(), "anonymized.fit")
+	fittest.WriteFile(t, path, act)
+	inspections, err := Inspect(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range inspections[0].Messages {
+		if m.Message == "user_profile" {
+			t.Errorf("Expected no user profile")
+		}
+	}
+
+	act, err = ReadActivity(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if act.FileId.SerialNumber != basetype.Uint32zInvalid {
+		t.Errorf("Expected no serial number of file id, Got: %d", act.FileId.SerialNumber)
+	}
+	if len(act.DeviceInfos) != 1 {
+		t.Errorf("Expected 1 device info, Got: %d", len(act.DeviceInfos))
+	}
+	for _, d := range act.DeviceInfos {
+		if d.SerialNumber != basetype.Uint32zInvalid {
+			t.Errorf("Expected no serial number of device, Got: %d", d.SerialNumber)
+		}
+	}
+	if act.UserProfile != nil {
+		t.Errorf("Expected no user profile")
+	}
+}
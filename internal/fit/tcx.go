@@ -0,0 +1,231 @@
+package fit
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/sectore/fit-activities-tui/internal/common"
+)
+
+// Training Center XML (TCX) v2 incl. `ActivityExtension` v2 (speed, power, run cadence)
+type tcxFile struct {
+	Activities []struct {
+		Sport string   `xml:"Sport,attr"`
+		Laps  []tcxLap `xml:"Lap"`
+	} `xml:"Activities>Activity"`
+}
+
+type tcxLap struct {
+	StartTime        string          `xml:"StartTime,attr"`
+	TotalTimeSeconds *float64        `xml:"TotalTimeSeconds"`
+	DistanceMeters   *float64        `xml:"DistanceMeters"`
+	MaximumSpeed     *float64        `xml:"MaximumSpeed"`
+	AvgHeartrate     *uint8          `xml:"AverageHeartRateBpm>Value"`
+	MaxHeartrate     *uint8          `xml:"MaximumHeartRateBpm>Value"`
+	Trackpoints      []tcxTrackpoint `xml:"Track>Trackpoint"`
+}
+
+type tcxTrackpoint struct {
+	Time     string `xml:"Time"`
+	Position *struct {
+		Lat  float64 `xml:"LatitudeDegrees"`
+		Long float64 `xml:"LongitudeDegrees"`
+	} `xml:"Position"`
+	AltitudeMeters *float64 `xml:"AltitudeMeters"`
+	DistanceMeters *float64 `xml:"DistanceMeters"`
+	Heartrate      *uint8   `xml:"HeartRateBpm>Value"`
+	Cadence        *uint8   `xml:"Cadence"`
+	Extensions     struct {
+		TPX struct {
+			Speed      *float64 `xml:"Speed"`
+			Watts      *uint16  `xml:"Watts"`
+			RunCadence *uint8   `xml:"RunCadence"`
+		} `xml:"TPX"`
+	} `xml:"Extensions"`
+}
+
+// meters -> `Distance` (cm)
+func metersToDistance(meters float64) common.Distance {
+	return common.NewDistance(uint32(math.Round(meters * 100)))
+}
+
+// seconds -> `Duration` (ms)
+func secondsToDuration(seconds float64) common.Duration {
+	return common.NewDuration(uint32(math.Round(seconds * 1000)))
+}
+
+// m/s -> `Speed` (mm/s)
+func metersPerSecondToSpeed(speed float64) common.Speed {
+	return common.NewSpeed(float32(speed * 1000))
+}
+
+func parseTCXTrackpoint(tp tcxTrackpoint) common.RecordData {
+	record := common.RecordData{
+		Time: parseXMLTime(tp.Time),
+	}
+	if tp.Position != nil {
+		record.Position = common.Ptr(common.NewPosition(tp.Position.Lat, tp.Position.Long))
+	}
+	if tp.AltitudeMeters != nil {
+		record.Altitude = common.Ptr(common.NewAltitude(*tp.AltitudeMeters))
+	}
+	if tp.DistanceMeters != nil {
+		record.Distance = common.Ptr(metersToDistance(*tp.DistanceMeters))
+	}
+	if tp.Heartrate != nil {
+		record.Heartrate = common.Ptr(common.NewHeartrate(*tp.Heartrate))
+	}
+	// `Cadence` of cycling, `RunCadence` of running
+	if tp.Cadence != nil {
+		record.Cadence = common.Ptr(common.NewCadence(*tp.Cadence))
+	} else if tp.Extensions.TPX.RunCadence != nil {
+		record.Cadence = common.Ptr(common.NewCadence(*tp.Extensions.TPX.RunCadence))
+	}
+	if tp.Extensions.TPX.Speed != nil {
+		record.Speed = common.Ptr(metersPerSecondToSpeed(*tp.Extensions.TPX.Speed))
+	}
+	if tp.Extensions.TPX.Watts != nil {
+		record.Power = common.Ptr(common.NewPower(*tp.Extensions.TPX.Watts))
+	}
+	return record
+}
+
+func parseTCXLap(l tcxLap, records []common.RecordData) common.LapData {
+	lap := common.LapData{
+		StartTime: parseXMLTime(l.StartTime),
+	}
+	// last record is the end of the lap
+	for i := len(records) - 1; i >= 0; i-- {
+		if records[i].Time != nil {
+			lap.FinishTime = records[i].Time
+			break
+		}
+	}
+	if lap.StartTime == nil && len(records) > 0 {
+		lap.StartTime = records[0].Time
+	}
+
+	if lap.StartTime != nil && lap.FinishTime != nil {
+		d := common.NewDuration(uint32(lap.FinishTime.Value.Sub(lap.StartTime.Value).Milliseconds()))
+		lap.Duration.Total = common.Ptr(d)
+	}
+	if l.TotalTimeSeconds != nil {
+		d := secondsToDuration(*l.TotalTimeSeconds)
+		lap.Duration.Active = common.Ptr(d)
+	}
+	if lap.Duration.Total != nil && lap.Duration.Active != nil &&
+		lap.Duration.Total.Value >= lap.Duration.Active.Value {
+		d := common.NewDuration(lap.Duration.Total.Value - lap.Duration.Active.Value)
+		lap.Duration.Pause = common.Ptr(d)
+	}
+
+	if l.DistanceMeters != nil {
+		d := metersToDistance(*l.DistanceMeters)
+		lap.Distance = common.Ptr(d)
+		if l.TotalTimeSeconds != nil && *l.TotalTimeSeconds > 0 {
+			speed := metersPerSecondToSpeed(*l.DistanceMeters / *l.TotalTimeSeconds)
+			lap.Speed.Avg = common.Ptr(speed)
+		}
+	}
+	if l.MaximumSpeed != nil {
+		speed := metersPerSecondToSpeed(*l.MaximumSpeed)
+		lap.Speed.Max = common.Ptr(speed)
+	}
+
+	if l.AvgHeartrate != nil {
+		hr := common.NewHeartrate(*l.AvgHeartrate)
+		lap.Heartrate.Avg = common.Ptr(hr)
+	}
+	if l.MaxHeartrate != nil {
+		hr := common.NewHeartrate(*l.MaxHeartrate)
+		lap.Heartrate.Max = common.Ptr(hr)
+	}
+
+	lap.Elevation = common.ElevationOfRecords(records, common.ElevationThreshold)
+
+	return lap
+}
+
+func parseTCX(r io.Reader, file string) (*common.ActivityData, error) {
+	var tcx tcxFile
+	if err := xml.NewDecoder(r).Decode(&tcx); err != nil {
+		return nil, fmt.Errorf("invalid TCX (file %s): %v", file, err)
+	}
+
+	if len(tcx.Activities) <= 0 {
+		return nil, fmt.Errorf("no Activity found (file %s)", file)
+	}
+
+	var records []common.RecordData
+	var laps []common.LapData
+	var totalDistance *common.Distance
+	var activeDuration *common.Duration
+	sport := common.NewSport(common.SportGeneric, "")
+
+	for index, act := range tcx.Activities {
+		name := parseSportName(act.Sport)
+		if index == 0 {
+			sport = common.NewSport(name, "")
+		} else if sport.Name != name {
+			sport = common.NewSport(common.SportMultisport, "")
+		}
+
+		for _, l := range act.Laps {
+			var lapRecords []common.RecordData
+			for _, tp := range l.Trackpoints {
+				lapRecords = append(lapRecords, parseTCXTrackpoint(tp))
+			}
+			records = append(records, lapRecords...)
+			laps = append(laps, parseTCXLap(l, lapRecords))
+
+			if l.DistanceMeters != nil {
+				d := metersToDistance(*l.DistanceMeters)
+				if totalDistance == nil {
+					totalDistance = common.Ptr(d)
+				} else {
+					totalDistance.Value += d.Value
+				}
+			}
+			if l.TotalTimeSeconds != nil {
+				d := secondsToDuration(*l.TotalTimeSeconds)
+				if activeDuration == nil {
+					activeDuration = common.Ptr(d)
+				} else {
+					activeDuration.Value += d.Value
+				}
+			}
+		}
+	}
+
+	if len(records) <= 0 {
+		return nil, fmt.Errorf("no Records found (file %s)", file)
+	}
+
+	// Distances and speed are optional
+	common.AddDistances(records)
+	common.AddSpeeds(records)
+
+	data := common.SummarizeRecords(records)
+
+	// totals of laps are preferred over totals calculated by records
+	if totalDistance != nil {
+		data.TotalDistance = totalDistance
+	}
+	if activeDuration != nil && data.Duration.Total != nil {
+		data.Duration.Active = activeDuration
+		pause := common.NewDuration(0)
+		if data.Duration.Total.Value > activeDuration.Value {
+			pause.Value = data.Duration.Total.Value - activeDuration.Value
+		}
+		data.Duration.Pause = common.Ptr(pause)
+	}
+	data.NoSessions = uint32(len(tcx.Activities))
+	data.Laps = laps
+	data.Sport = sport
+	// TCX doesn't include ascents / descents
+	data.TotalsByRecords = true
+
+	return &data, nil
+}
@@ -0,0 +1,245 @@
+package fit
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/muktihari/fit/decoder"
+	"github.com/muktihari/fit/profile/filedef"
+	"github.com/sectore/fit-activities-tui/internal/common"
+)
+
+type Severity string
+
+const (
+	// File is corrupt or can't be parsed
+	SeverityError Severity = "error"
+	// File can be parsed, but includes suspicious values
+	SeverityWarning Severity = "warning"
+)
+
+// Names of all checks
+const (
+	CheckCRC        = "crc"
+	CheckStructure  = "structure"
+	CheckChained    = "chained"
+	CheckSessions   = "sessions"
+	CheckRecords    = "records"
+	CheckTimestamps = "timestamps"
+	CheckDistance   = "distance"
+	CheckSpeed      = "speed"
+	CheckHeartrate  = "heartrate"
+)
+
+// Values above these limits are implausible
+const (
+	// 150 km/h in mm/s
+	MaxPlausibleSpeed     = 41_667
+	MaxPlausibleHeartrate = 250
+)
+
+type Issue struct {
+	Severity Severity `json:"severity"`
+	Check    string   `json:"check"`
+	Message  string   `json:"message"`
+	// Number of records with the same issue (checks of records only)
+	Count int `json:"count,omitempty"`
+	// Number of the first record with this issue, starting at 1 (checks of records only)
+	Record *int `json:"record,omitempty"`
+}
+
+// Result of `Validate`
+type Validation struct {
+	File string `json:"file"`
+	// Number of (chained) FIT files, 1 for GPX or TCX files
+	Files  int     `json:"files"`
+	Issues []Issue `json:"issues"`
+}
+
+func (v Validation) hasSeverity(severity Severity) bool {
+	for _, issue := range v.Issues {
+		if issue.Severity == severity {
+			return true
+		}
+	}
+	return false
+}
+
+func (v Validation) HasErrors() bool {
+	return v.hasSeverity(SeverityError)
+}
+
+func (v Validation) HasWarnings() bool {
+	return v.hasSeverity(SeverityWarning)
+}
+
+func (v *Validation) add(severity Severity, check string, format string, args ...any) {
+	v.Issues = append(v.Issues, Issue{
+		Severity: severity,
+		Check:    check,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// Collects records with the same issue to report them once
+type recordIssue struct {
+	check   string
+	message string
+	count   int
+	first   int
+}
+
+func (ri *recordIssue) add(index int) {
+	if ri.count == 0 {
+		ri.first = index
+	}
+	ri.count += 1
+}
+
+// Checks records for implausible values: timestamps going backwards,
+// decreasing distances, speed spikes and heartrates above `MaxPlausibleHeartrate`.
+// Speed is checked by values of records and by distances between records.
+func ValidateRecords(records []common.RecordData) []Issue {
+	issues := []*recordIssue{
+		{check: CheckTimestamps, message: "timestamps going backwards"},
+		{check: CheckDistance, message: "decreasing distances"},
+		{check: CheckSpeed, message: fmt.Sprintf("speed above %s", common.NewSpeed(MaxPlausibleSpeed).Format())},
+		{check: CheckHeartrate, message: fmt.Sprintf("heartrate above %dbpm", MaxPlausibleHeartrate)},
+	}
+	timestamps, distance, speed, heartrate := issues[0], issues[1], issues[2], issues[3]
+
+	var prevTime, prevDistanceTime *common.RecordData
+	for i, r := range records {
+		if r.Time != nil {
+			if prevTime != nil && r.Time.Value.Before(prevTime.Time.Value) {
+				timestamps.add(i)
+			}
+			prevTime = &records[i]
+		}
+
+		if r.Heartrate != nil && r.Heartrate.Value > MaxPlausibleHeartrate {
+			heartrate.add(i)
+		}
+
+		spike := r.Speed != nil && r.Speed.Value > MaxPlausibleSpeed
+		if r.Time != nil && r.Distance != nil {
+			if prev := prevDistanceTime; prev != nil {
+				if r.Distance.Value < prev.Distance.Value {
+					distance.add(i)
+				} else if seconds := r.Time.Value.Sub(prev.Time.Value).Seconds(); seconds > 0 {
+					// cm/s -> mm/s
+					s := float64(r.Distance.Value-prev.Distance.Value) * 10 / seconds
+					spike = spike || s > MaxPlausibleSpeed
+				}
+			}
+			prevDistanceTime = &records[i]
+		}
+		if spike {
+			speed.add(i)
+		}
+	}
+
+	var result []Issue
+	for _, ri := range issues {
+		if ri.count == 0 {
+			continue
+		}
+		result = append(result, Issue{
+			Severity: SeverityWarning,
+			Check:    ri.check,
+			Message:  fmt.Sprintf("%d records with %s (first: record %d)", ri.count, ri.message, ri.first+1),
+			Count:    ri.count,
+			Record:   common.Ptr(ri.first + 1),
+		})
+	}
+	return result
+}
+
+// Checks integrity (CRC) of all FIT files and boundaries of chained FIT files
+func validateIntegrity(path string, v *Validation) {
+	f, err := Open(path)
+	if err != nil {
+		v.add(SeverityError, CheckStructure, "%v", err)
+		return
+	}
+	defer f.Close()
+
+	dec := decoder.New(f)
+	seq, err := dec.CheckIntegrity()
+	switch {
+	case err == nil:
+	case errors.Is(err, decoder.ErrCRCChecksumMismatch):
+		v.add(SeverityError, CheckCRC, "invalid CRC of FIT file %d: %v", seq+1, err)
+	case errors.Is(err, decoder.ErrNotFITFile) && seq > 0:
+		v.add(SeverityError, CheckChained, "unexpected data after FIT file %d: %v", seq, err)
+	default:
+		v.add(SeverityError, CheckStructure, "invalid FIT file %d: %v", seq+1, err)
+	}
+}
+
+// Checks content of all (chained) FIT files: sessions, records and values of records
+func validateContent(path string, v *Validation) {
+	f, err := Open(path)
+	if err != nil {
+		v.add(SeverityError, CheckStructure, "%v", err)
+		return
+	}
+	defer f.Close()
+
+	lis := filedef.NewListener()
+	defer lis.Close()
+
+	// CRC is checked by `validateIntegrity` already
+	dec := decoder.New(f,
+		decoder.WithMesgListener(lis),
+		decoder.WithBroadcastOnly(),
+		decoder.WithIgnoreChecksum(),
+	)
+	for dec.Next() {
+		if _, err := dec.Decode(); err != nil {
+			// errors of decoding are reported by `validateIntegrity` already (most likely)
+			if !v.HasErrors() {
+				v.add(SeverityError, CheckStructure, "%v", err)
+			}
+			return
+		}
+		v.Files += 1
+
+		act, ok := lis.File().(*filedef.Activity)
+		if !ok {
+			v.add(SeverityWarning, CheckStructure, "FIT file %d is not an activity", v.Files)
+			continue
+		}
+		if len(act.Sessions) <= 0 {
+			v.add(SeverityError, CheckSessions, "no Sessions found in FIT file %d", v.Files)
+		}
+		if len(act.Records) <= 0 {
+			v.add(SeverityError, CheckRecords, "no Records found in FIT file %d", v.Files)
+			continue
+		}
+		v.Issues = append(v.Issues, ValidateRecords(parseRecords(act.Records))...)
+	}
+}
+
+// Validates an activity file:
+// - FIT files: CRC of header and data, boundaries of chained files,
+// missing sessions or records and implausible values of records (see `ValidateRecords`)
+// - GPX and TCX files: structure and implausible values of records
+func Validate(path string) Validation {
+	v := Validation{File: path, Issues: []Issue{}}
+
+	if ext := fileExt(path); ext == gpxExt || ext == tcxExt {
+		data, err := ParseFile(path)
+		if err != nil {
+			v.add(SeverityError, CheckStructure, "%v", err)
+			return v
+		}
+		v.Files = 1
+		v.Issues = append(v.Issues, ValidateRecords(data.Records)...)
+		return v
+	}
+
+	validateIntegrity(path, &v)
+	validateContent(path, &v)
+	return v
+}
@@ -0,0 +1,126 @@
+package fit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sectore/fit-activities-tui/internal/common"
+	"github.com/sectore/fit-activities-tui/internal/fit/fittest"
+)
+
+func checksOf(v Validation) []string {
+	var checks []string
+	for _, issue := range v.Issues {
+		checks = append(checks, issue.Check)
+	}
+	return checks
+}
+
+func TestValidate(t *testing.T) {
+	dir := t.TempDir()
+
+	valid := filepath.Join(dir, "valid.fit")
+	fittest.WriteFile(t, valid, fittest.Activity(3), fittest.Activity(2))
+
+	noSessions := filepath.Join(dir, "no-sessions.fit")
+	act := fittest.Activity(3)
+	act.Sessions = nil
+	fittest.WriteFile(t, noSessions, act)
+
+	content, err := os.ReadFile(valid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	corrupt := filepath.Join(dir, "corrupt.fit")
+	corruptContent := append([]byte{}, content...)
+	// change CRC of the last (chained) file
+	corruptContent[len(corruptContent)-1] ^= 0xFF
+	if err := os.WriteFile(corrupt, corruptContent, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	trailing := filepath.Join(dir, "trailing.fit")
+	if err := os.WriteFile(trailing, append(append([]byte{}, content...), []byte("garbage")...), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path      string
+		files     int
+		checks    []string
+		hasErrors bool
+	}{
+		{valid, 2, nil, false},
+		{noSessions, 1, []string{CheckSessions}, true},
+		{corrupt, 2, []string{CheckCRC}, true},
+		{trailing, 2, []string{CheckChained}, true},
+		{filepath.Join("testdata", "ride.gpx"), 1, nil, false},
+	}
+
+	for _, test := range tests {
+		v := Validate(test.path)
+		name := filepath.Base(test.path)
+		if v.Files != test.files {
+			t.Errorf("%s: Expected files: %d, Got: %d", name, test.files, v.Files)
+		}
+		checks := checksOf(v)
+		if len(checks) != len(test.checks) {
+			t.Errorf("%s: Expected checks: %v, Got: %v (%+v)", name, test.checks, checks, v.Issues)
+			continue
+		}
+		for i := range checks {
+			if checks[i] != test.checks[i] {
+				t.Errorf("%s: Expected checks: %v, Got: %v", name, test.checks, checks)
+			}
+		}
+		if v.HasErrors() != test.hasErrors {
+			t.Errorf("%s: Expected errors: %v, Got: %v", name, test.hasErrors, v.HasErrors())
+		}
+	}
+}
+
+func TestValidateRecords(t *testing.T) {
+	start := time.Date(2025, 6, 1, 8, 0, 0, 0, time.UTC)
+	record := func(seconds int, meters uint32, bpm uint8) common.RecordData {
+		return common.RecordData{
+			Time:      common.Ptr(common.NewTime(start.Add(time.Duration(seconds) * time.Second))),
+			Distance:  common.Ptr(common.NewDistance(meters * 100)),
+			Heartrate: common.Ptr(common.NewHeartrate(bpm)),
+		}
+	}
+
+	tests := []struct {
+		name    string
+		records []common.RecordData
+		checks  []string
+		// first record with an issue (starting at 1)
+		first int
+	}{
+		{"valid", []common.RecordData{record(0, 0, 120), record(1, 5, 121), record(2, 10, 122)}, nil, 0},
+		{"timestamps", []common.RecordData{record(0, 0, 120), record(2, 5, 121), record(1, 10, 122)}, []string{CheckTimestamps}, 3},
+		{"distance", []common.RecordData{record(0, 0, 120), record(1, 5, 121), record(2, 4, 122)}, []string{CheckDistance}, 3},
+		// 100m in a second
+		{"speed", []common.RecordData{record(0, 0, 120), record(1, 100, 121), record(2, 105, 122)}, []string{CheckSpeed}, 2},
+		{"heartrate", []common.RecordData{record(0, 0, 251), record(1, 5, 121)}, []string{CheckHeartrate}, 1},
+	}
+
+	for _, test := range tests {
+		issues := ValidateRecords(test.records)
+		if len(issues) != len(test.checks) {
+			t.Errorf("%s: Expected checks: %v, Got: %+v", test.name, test.checks, issues)
+			continue
+		}
+		for i, issue := range issues {
+			if issue.Check != test.checks[i] {
+				t.Errorf("%s: Expected check: %s, Got: %s", test.name, test.checks[i], issue.Check)
+			}
+			if issue.Severity != SeverityWarning {
+				t.Errorf("%s: Expected severity: %s, Got: %s", test.name, SeverityWarning, issue.Severity)
+			}
+			if issue.Record == nil || *issue.Record != test.first {
+				t.Errorf("%s: Expected first record: %d, Got: %v", test.name, test.first, issue.Record)
+			}
+		}
+	}
+}
@@ -0,0 +1,320 @@
+// Package query implements a small query language to filter activities, e.g.
+//
+//	sport:cycling dist>50km date:2025-06 hr.avg<140 file:*wahoo*
+//
+// A query contains terms separated by spaces. All terms need to match (AND).
+// A term is either a `key`, an operator and a value or a free text to search for.
+// Terms can be negated with a leading `-` (e.g. `-sport:running`).
+package query
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sectore/fit-activities-tui/internal/common"
+)
+
+type Operator string
+
+const (
+	OpMatch        Operator = ":"
+	OpEqual        Operator = "="
+	OpNotEqual     Operator = "!="
+	OpLess         Operator = "<"
+	OpLessEqual    Operator = "<="
+	OpGreater      Operator = ">"
+	OpGreaterEqual Operator = ">="
+)
+
+type kind int
+
+const (
+	kindText kind = iota
+	kindDistance
+	kindDuration
+	kindNumber
+)
+
+// All supported keys incl. their kind of value
+var keys = map[string]kind{
+	common.FilterKeyText:        kindText,
+	common.FilterKeySport:       kindText,
+	common.FilterKeySubSport:    kindText,
+	common.FilterKeyDate:        kindText,
+	common.FilterKeyFile:        kindText,
+	common.FilterKeyDistance:    kindDistance,
+	common.FilterKeyDuration:    kindDuration,
+	common.FilterKeyActive:      kindDuration,
+	common.FilterKeyPause:       kindDuration,
+	common.FilterKeyAscent:      kindNumber,
+	common.FilterKeyDescent:     kindNumber,
+	common.FilterKeyAltitudeMin: kindNumber,
+	common.FilterKeyAltitudeMax: kindNumber,
+	common.FilterKeySpeedAvg:    kindNumber,
+	common.FilterKeySpeedMax:    kindNumber,
+	common.FilterKeyHrAvg:       kindNumber,
+	common.FilterKeyHrMin:       kindNumber,
+	common.FilterKeyHrMax:       kindNumber,
+	common.FilterKeyCadenceAvg:  kindNumber,
+	common.FilterKeyCadenceMax:  kindNumber,
+	common.FilterKeyPowerAvg:    kindNumber,
+	common.FilterKeyPowerMax:    kindNumber,
+	common.FilterKeyPowerNp:     kindNumber,
+	common.FilterKeyTempAvg:     kindNumber,
+	common.FilterKeyTempMin:     kindNumber,
+	common.FilterKeyTempMax:     kindNumber,
+	common.FilterKeyRecords:     kindNumber,
+	common.FilterKeyLaps:        kindNumber,
+	common.FilterKeySessions:    kindNumber,
+}
+
+// Shorthands of keys
+var aliases = map[string]string{
+	"distance": common.FilterKeyDistance,
+	"duration": common.FilterKeyDuration,
+	"time":     common.FilterKeyDuration,
+	"speed":    common.FilterKeySpeedAvg,
+	"hr":       common.FilterKeyHrAvg,
+	"cad":      common.FilterKeyCadenceAvg,
+	"cadence":  common.FilterKeyCadenceAvg,
+	"power":    common.FilterKeyPowerAvg,
+	"np":       common.FilterKeyPowerNp,
+	"temp":     common.FilterKeyTempAvg,
+	"up":       common.FilterKeyAscent,
+	"down":     common.FilterKeyDescent,
+}
+
+// Units (suffixes) of numeric values, which are stripped before parsing
+var units = []string{"km/h", "kmh", "bpm", "rpm", "°c", "c", "w", "m"}
+
+type term struct {
+	negate bool
+	key    string
+	op     Operator
+	value  string
+	number float64
+}
+
+type Query struct {
+	terms []term
+}
+
+// key, operator (longest first) and value of a term
+var termRegex = regexp.MustCompile(`^([a-z][a-z.]*)(>=|<=|!=|:|=|>|<)(.*)$`)
+
+// Parses given input into a `Query`
+func Parse(input string) (Query, error) {
+	var q Query
+	for _, token := range tokenize(input) {
+		t := term{}
+		if strings.HasPrefix(token, "-") && len(token) > 1 {
+			t.negate = true
+			token = token[1:]
+		}
+
+		match := termRegex.FindStringSubmatch(strings.ToLower(token))
+		key := ""
+		if match != nil {
+			key = match[1]
+			if alias, ok := aliases[key]; ok {
+				key = alias
+			}
+		}
+		kind, known := keys[key]
+
+		// free text: no key or unknown key without a typical operator (e.g. time "10:00")
+		if match == nil || (!known && Operator(match[2]) == OpMatch && !isKeyLike(match[1])) {
+			t.key = common.FilterKeyText
+			t.op = OpMatch
+			t.value = strings.ToLower(unquote(token))
+			q.terms = append(q.terms, t)
+			continue
+		}
+
+		if !known {
+			return Query{}, fmt.Errorf("unknown filter key %q", match[1])
+		}
+
+		t.key = key
+		t.op = Operator(match[2])
+		// keep original case of value (e.g. for file names)
+		t.value = unquote(token[len(match[1])+len(match[2]):])
+		if t.value == "" {
+			return Query{}, fmt.Errorf("missing value of %q", match[1])
+		}
+
+		if kind != kindText {
+			number, err := parseNumber(kind, t.value)
+			if err != nil {
+				return Query{}, fmt.Errorf("invalid value of %q: %v", match[1], err)
+			}
+			t.number = number
+		} else {
+			t.value = strings.ToLower(t.value)
+		}
+
+		q.terms = append(q.terms, t)
+	}
+	return q, nil
+}
+
+// Keys contain letters and dots only (e.g. `hr.avg`), but not `http` etc.
+func isKeyLike(key string) bool {
+	return strings.Contains(key, ".")
+}
+
+// Splits input by spaces, but keeps quoted values (e.g. `file:"my ride.fit"`) together
+func tokenize(input string) []string {
+	var tokens []string
+	var sb strings.Builder
+	quoted := false
+	for _, r := range input {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			sb.WriteRune(r)
+		case r == ' ' && !quoted:
+			if sb.Len() > 0 {
+				tokens = append(tokens, sb.String())
+				sb.Reset()
+			}
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	if sb.Len() > 0 {
+		tokens = append(tokens, sb.String())
+	}
+	return tokens
+}
+
+func unquote(value string) string {
+	return strings.ReplaceAll(value, `"`, "")
+}
+
+// Parses numeric values into base units of `FilterFields`:
+// - distances: kilometers by default, `m` or `km` suffix allowed
+// - durations: minutes by default, Go durations allowed (e.g. `1h30m`, `45s`)
+// - numbers: units suffixes (e.g. `bpm`, `w`) are ignored
+func parseNumber(k kind, value string) (float64, error) {
+	value = strings.ToLower(value)
+	switch k {
+	case kindDistance:
+		if v, ok := strings.CutSuffix(value, "km"); ok {
+			return parseFloat(v, 1000)
+		}
+		if v, ok := strings.CutSuffix(value, "m"); ok {
+			return parseFloat(v, 1)
+		}
+		return parseFloat(value, 1000)
+	case kindDuration:
+		if _, err := strconv.ParseFloat(value, 64); err == nil {
+			return parseFloat(value, 60)
+		}
+		d, err := time.ParseDuration(strings.ReplaceAll(value, "min", "m"))
+		if err != nil {
+			return 0, fmt.Errorf("%q is not a duration", value)
+		}
+		return d.Seconds(), nil
+	default:
+		for _, unit := range units {
+			if v, ok := strings.CutSuffix(value, unit); ok {
+				return parseFloat(v, 1)
+			}
+		}
+		return parseFloat(value, 1)
+	}
+}
+
+func parseFloat(value string, factor float64) (float64, error) {
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a number", value)
+	}
+	return v * factor, nil
+}
+
+// Checks whether the query has no terms
+func (q Query) IsEmpty() bool {
+	return len(q.terms) == 0
+}
+
+// Checks whether all terms of the query match given fields
+func (q Query) Match(ff common.FilterFields) bool {
+	for _, t := range q.terms {
+		if t.match(ff) == t.negate {
+			return false
+		}
+	}
+	return true
+}
+
+func (t term) match(ff common.FilterFields) bool {
+	value, ok := ff[t.key]
+	if !ok {
+		return false
+	}
+
+	if keys[t.key] == kindText {
+		return t.matchText(strings.ToLower(value))
+	}
+
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return false
+	}
+	return compare(number, t.op, t.number)
+}
+
+func (t term) matchText(value string) bool {
+	switch t.op {
+	case OpMatch:
+		if strings.ContainsAny(t.value, "*?[") {
+			ok, _ := filepath.Match(t.value, value)
+			return ok
+		}
+		switch t.key {
+		case common.FilterKeyText, common.FilterKeyFile:
+			return strings.Contains(value, t.value)
+		case common.FilterKeyDate:
+			return strings.HasPrefix(value, t.value)
+		default:
+			return value == t.value
+		}
+	case OpEqual:
+		return value == t.value
+	case OpNotEqual:
+		return value != t.value
+	case OpLess:
+		return value < t.value
+	case OpLessEqual:
+		return value <= t.value
+	case OpGreater:
+		return value > t.value
+	case OpGreaterEqual:
+		return value >= t.value
+	}
+	return false
+}
+
+func compare(a float64, op Operator, b float64) bool {
+	switch op {
+	case OpMatch, OpEqual:
+		return a == b
+	case OpNotEqual:
+		return a != b
+	case OpLess:
+		return a < b
+	case OpLessEqual:
+		return a <= b
+	case OpGreater:
+		return a > b
+	case OpGreaterEqual:
+		return a >= b
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/sectore/fit-activities-tui/internal/common"
+)
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{name: "unknown key", input: "foo>10"},
+		{name: "unknown key with dot", input: "hr.median:140"},
+		{name: "missing value", input: "dist>"},
+		{name: "invalid number", input: "hr.avg<abc"},
+		{name: "invalid duration", input: "dur>1x"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(tt.input); err == nil {
+				t.Errorf("Expected error for %q", tt.input)
+			}
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	fields := common.FilterFields{
+		common.FilterKeyText:     "2025-06-14-wahoo.fit cycling 14.06.25 10:00",
+		common.FilterKeyFile:     "2025-06-14-Wahoo.fit",
+		common.FilterKeySport:    "cycling",
+		common.FilterKeySubSport: "road",
+		common.FilterKeyDate:     "2025-06-14 10:00",
+		common.FilterKeyDistance: "62345.6",
+		common.FilterKeyDuration: "9000",
+		common.FilterKeyHrAvg:    "135",
+		common.FilterKeySpeedAvg: "27.4",
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		expected bool
+	}{
+		{name: "empty query", input: "", expected: true},
+		{name: "sport", input: "sport:cycling", expected: true},
+		{name: "sport case insensitive", input: "sport:Cycling", expected: true},
+		{name: "other sport", input: "sport:running", expected: false},
+		{name: "negated sport", input: "-sport:running", expected: true},
+		{name: "distance in km", input: "dist>50km", expected: true},
+		{name: "distance default km", input: "dist<50", expected: false},
+		{name: "distance in m", input: "dist>=62345m", expected: true},
+		{name: "date prefix", input: "date:2025-06", expected: true},
+		{name: "date range", input: "date>=2025-06-01 date<2025-07", expected: true},
+		{name: "date other month", input: "date:2025-07", expected: false},
+		{name: "heart rate", input: "hr.avg<140", expected: true},
+		{name: "heart rate alias", input: "hr>140", expected: false},
+		{name: "heart rate with unit", input: "hr.avg<=135bpm", expected: true},
+		{name: "duration default minutes", input: "dur>=150", expected: true},
+		{name: "duration go format", input: "dur>2h30m", expected: false},
+		{name: "speed", input: "speed.avg>25km/h", expected: true},
+		{name: "file glob", input: "file:*wahoo*", expected: true},
+		{name: "file substring", input: "file:garmin", expected: false},
+		{name: "missing value", input: "power.avg>100", expected: false},
+		{name: "free text", input: "14.06.25", expected: true},
+		{name: "free text time", input: "10:00", expected: true},
+		{name: "all terms", input: "sport:cycling dist>50km date:2025-06 hr.avg<140 file:*wahoo*", expected: true},
+		{name: "one term fails", input: "sport:cycling dist>100km", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result := q.Match(fields); result != tt.expected {
+				t.Errorf("Expected: %v, Got: %v", tt.expected, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package tui
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+	"github.com/sectore/fit-activities-tui/internal/common"
+)
+
+// `LapsView` renders a table of all laps of an activity.
+// The lap holding given `currentRecord` is marked.
+func LapsView(ad common.ActivityData, currentRecord common.RecordData) string {
+	if len(ad.Laps) == 0 {
+		return i("No laps found.")
+	}
+
+	currentLap := ad.LapIndex(currentRecord)
+
+	speedLabel := "speed"
+	if ad.Sport.IsRunning() {
+		speedLabel = "pace"
+	}
+
+	// helper to format optional values
+	format := func(ok bool, f func() string) string {
+		if ok {
+			return f()
+		}
+		return "-"
+	}
+
+	var rows [][]string
+	for index, lap := range ad.Laps {
+		marker := ""
+		if index == currentLap {
+			marker = BulletPointBig
+		}
+		rows = append(rows, []string{
+			marker,
+			fmt.Sprintf("%d", index+1),
+			format(lap.Duration.Active != nil, func() string { return lap.Duration.Active.Format() }),
+			format(lap.Distance != nil, func() string { return lap.Distance.Format2() }),
+			format(lap.Speed.Avg != nil, func() string { return ad.Sport.FormatSpeed(*lap.Speed.Avg) }),
+			format(lap.Speed.Max != nil, func() string { return ad.Sport.FormatSpeed(*lap.Speed.Max) }),
+			format(lap.Heartrate.Avg != nil, func() string { return lap.Heartrate.Avg.Format() }),
+			format(lap.Heartrate.Max != nil, func() string { return lap.Heartrate.Max.Format() }),
+			format(lap.Elevation.Ascents != nil, func() string { return lap.Elevation.Ascents.Format() }),
+		})
+	}
+
+	t := table.New().
+		Headers("", "#", "time", "distance", "⌀ "+speedLabel, "max "+speedLabel, "⌀ ♥", "max ♥", arrowTop).
+		Rows(rows...).
+		Border(lipgloss.Border{}).
+		StyleFunc(func(row, col int) lipgloss.Style {
+			style := lipgloss.NewStyle().PaddingRight(2)
+			switch {
+			case row == table.HeaderRow:
+				return style.Bold(true)
+			case row == currentLap:
+				return style.Bold(true)
+			default:
+				return style
+			}
+		})
+
+	return fmt.Sprintf("%s", t)
+}
@@ -45,8 +45,8 @@ func NewListDelegate(spinner *spinner.Model) listDelegate {
 }
 
 func (d *listDelegate) Render(w io.Writer, m list.Model, index int, item list.Item) {
-	// `ActivityAD` is our custom `Item`
-	if act, ok := item.(common.Activity); ok {
+	// `Activity` is our custom `Item`
+	if act, ok := item.(*common.Activity); ok {
 		_, _, loading := asyncdata.Loading(act.Data)
 		notAsked := asyncdata.NotAsked(act.Data)
 		if loading || notAsked {
@@ -54,8 +54,19 @@ func (d *listDelegate) Render(w io.Writer, m list.Model, index int, item list.It
 			fmt.Fprintf(w, "%s", d.Spinner.View())
 			return
 		}
+		// render `Failure` by using error colors
+		if _, ok := asyncdata.Failure(act.Data); ok {
+			fd := d.DefaultDelegate
+			fd.Styles.NormalTitle = fd.Styles.NormalTitle.Foreground(errorColor)
+			fd.Styles.NormalDesc = fd.Styles.NormalDesc.Foreground(errorColor)
+			fd.Styles.DimmedTitle = fd.Styles.DimmedTitle.Foreground(errorColor)
+			fd.Styles.DimmedDesc = fd.Styles.DimmedDesc.Foreground(errorColor)
+			fd.Styles.SelectedTitle = fd.Styles.SelectedTitle.Foreground(errorColor).BorderForeground(errorColor)
+			fd.Styles.SelectedDesc = fd.Styles.SelectedDesc.Foreground(errorColor).BorderForeground(errorColor)
+			fd.Render(w, m, index, item)
+			return
+		}
 	}
-	// TODO: render `Failure`
 
 	// use default render
 	d.DefaultDelegate.Render(w, m, index, item)
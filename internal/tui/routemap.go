@@ -0,0 +1,212 @@
+package tui
+
+import (
+	"math"
+	"strings"
+
+	"github.com/sectore/fit-activities-tui/internal/common"
+)
+
+const (
+	// Number of dots of a single braille character
+	brailleDotsX = 2
+	brailleDotsY = 4
+	// Empty braille character (all other braille characters are based on it)
+	brailleBase = '⠀'
+)
+
+// Bits of each dot of a braille character, indexed by [y][x]
+var brailleDots = [brailleDotsY][brailleDotsX]rune{
+	{0x01, 0x08},
+	{0x02, 0x10},
+	{0x04, 0x20},
+	{0x40, 0x80},
+}
+
+// `BrailleCanvas` is a canvas to draw dots using braille characters.
+// Each character (cell) contains 2x4 dots.
+type BrailleCanvas struct {
+	width, height int
+	cells         [][]rune
+}
+
+// Creates a new `BrailleCanvas` by given size of cells (characters)
+func NewBrailleCanvas(width, height int) BrailleCanvas {
+	cells := make([][]rune, max(height, 0))
+	for y := range cells {
+		cells[y] = make([]rune, max(width, 0))
+	}
+	return BrailleCanvas{width: max(width, 0), height: max(height, 0), cells: cells}
+}
+
+// Width in dots
+func (c BrailleCanvas) DotsWidth() int {
+	return c.width * brailleDotsX
+}
+
+// Height in dots
+func (c BrailleCanvas) DotsHeight() int {
+	return c.height * brailleDotsY
+}
+
+// Sets a dot at given position. Dots out of the canvas are ignored.
+func (c *BrailleCanvas) Set(x, y int) {
+	if x < 0 || y < 0 || x >= c.DotsWidth() || y >= c.DotsHeight() {
+		return
+	}
+	c.cells[y/brailleDotsY][x/brailleDotsX] |= brailleDots[y%brailleDotsY][x%brailleDotsX]
+}
+
+// Draws a line between two dots (Bresenham's line algorithm)
+func (c *BrailleCanvas) Line(x0, y0, x1, y1 int) {
+	dx := abs(x1 - x0)
+	dy := -abs(y1 - y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	err := dx + dy
+	for {
+		c.Set(x0, y0)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * err
+		if e2 >= dy {
+			err += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			err += dx
+			y0 += sy
+		}
+	}
+}
+
+// Rows of characters. Empty cells are rendered as spaces.
+func (c BrailleCanvas) Rows() []string {
+	rows := make([]string, c.height)
+	for y, cells := range c.cells {
+		var sb strings.Builder
+		for _, cell := range cells {
+			if cell == 0 {
+				sb.WriteRune(' ')
+			} else {
+				sb.WriteRune(brailleBase + cell)
+			}
+		}
+		rows[y] = sb.String()
+	}
+	return rows
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// `RouteMap` draws the track of given records into a braille canvas of `width` x `height` characters.
+// The position of the record at `currentIndex` (or the latest position before) is marked.
+// Positions are projected using an equirectangular projection, which is fine for the size of an activity.
+// Returns an empty string if no positions are available.
+func RouteMap(records []common.RecordData, currentIndex int, width int, height int) string {
+	var positions []common.Position
+	for _, r := range records {
+		if r.Position != nil {
+			positions = append(positions, *r.Position)
+		}
+	}
+	if len(positions) == 0 || width <= 0 || height <= 0 {
+		return ""
+	}
+
+	// Bounds
+	minLat, maxLat := positions[0].Lat, positions[0].Lat
+	minLong, maxLong := positions[0].Long, positions[0].Long
+	for _, p := range positions {
+		minLat, maxLat = math.Min(minLat, p.Lat), math.Max(maxLat, p.Lat)
+		minLong, maxLong = math.Min(minLong, p.Long), math.Max(maxLong, p.Long)
+	}
+	// Shrink longitudes depending on latitude to keep proportions
+	lngFactor := math.Cos((minLat + maxLat) / 2 * math.Pi / 180)
+	spanX := (maxLong - minLong) * lngFactor
+	spanY := maxLat - minLat
+
+	canvas := NewBrailleCanvas(width, height)
+	dotsW := float64(canvas.DotsWidth() - 1)
+	dotsH := float64(canvas.DotsHeight() - 1)
+
+	// Same scale for both axes, limited by the larger span
+	scale := math.Inf(1)
+	if spanX > 0 {
+		scale = dotsW / spanX
+	}
+	if spanY > 0 {
+		scale = math.Min(scale, dotsH/spanY)
+	}
+	if math.IsInf(scale, 1) {
+		scale = 0
+	}
+	// Center track
+	offsetX := (dotsW - spanX*scale) / 2
+	offsetY := (dotsH - spanY*scale) / 2
+
+	project := func(p common.Position) (int, int) {
+		x := (p.Long-minLong)*lngFactor*scale + offsetX
+		// invert y: north is on top
+		y := dotsH - ((p.Lat-minLat)*scale + offsetY)
+		return int(math.Round(x)), int(math.Round(y))
+	}
+
+	prevX, prevY := project(positions[0])
+	for _, p := range positions {
+		x, y := project(p)
+		canvas.Line(prevX, prevY, x, y)
+		prevX, prevY = x, y
+	}
+
+	rows := canvas.Rows()
+
+	// Mark current position
+	var current *common.Position
+	for index := min(currentIndex, len(records)-1); index >= 0; index-- {
+		if records[index].Position != nil {
+			current = records[index].Position
+			break
+		}
+	}
+	if current != nil {
+		x, y := project(*current)
+		cellX, cellY := x/brailleDotsX, y/brailleDotsY
+		row := []rune(rows[cellY])
+		row[cellX] = []rune(BulletPointBig)[0]
+		rows[cellY] = string(row)
+	}
+
+	return strings.Join(rows, "\n")
+}
+
+const (
+	MapWidth  = BarWidth + 10
+	MapHeight = 20
+)
+
+// `MapView` renders the route of an activity incl. the position of the current `Record`
+func MapView(ad common.ActivityData, recordIndex int) string {
+	routeMap := RouteMap(ad.Records, recordIndex, MapWidth, MapHeight)
+	if routeMap == "" {
+		return i("No GPS data found.")
+	}
+
+	positionTxt := i(common.NoDataText)
+	if position := ad.Records[recordIndex].Position; position != nil {
+		positionTxt = position.Format()
+	}
+
+	return routeMap + br + br + b("position") + " " + positionTxt
+}
@@ -0,0 +1,111 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sectore/fit-activities-tui/internal/common"
+)
+
+func TestBrailleCanvas(t *testing.T) {
+	tests := []struct {
+		name     string
+		width    int
+		height   int
+		draw     func(c *BrailleCanvas)
+		expected []string
+	}{
+		{
+			name:     "empty",
+			width:    2,
+			height:   1,
+			draw:     func(c *BrailleCanvas) {},
+			expected: []string{"  "},
+		},
+		{
+			name:   "single dots",
+			width:  2,
+			height: 1,
+			draw: func(c *BrailleCanvas) {
+				c.Set(0, 0)
+				c.Set(3, 3)
+			},
+			expected: []string{"⠁⢀"},
+		},
+		{
+			name:   "dots out of canvas are ignored",
+			width:  1,
+			height: 1,
+			draw: func(c *BrailleCanvas) {
+				c.Set(-1, 0)
+				c.Set(2, 0)
+				c.Set(0, 4)
+			},
+			expected: []string{" "},
+		},
+		{
+			name:   "horizontal line",
+			width:  2,
+			height: 1,
+			draw: func(c *BrailleCanvas) {
+				c.Line(0, 0, 3, 0)
+			},
+			expected: []string{"⠉⠉"},
+		},
+		{
+			name:   "vertical line across rows",
+			width:  1,
+			height: 2,
+			draw: func(c *BrailleCanvas) {
+				c.Line(0, 0, 0, 7)
+			},
+			expected: []string{"⡇", "⡇"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewBrailleCanvas(tt.width, tt.height)
+			tt.draw(&c)
+			result := c.Rows()
+
+			if strings.Join(result, "\n") != strings.Join(tt.expected, "\n") {
+				t.Errorf("Expected:\n%s\nGot:\n%s", strings.Join(tt.expected, "\n"), strings.Join(result, "\n"))
+			}
+		})
+	}
+}
+
+func TestRouteMap(t *testing.T) {
+	position := func(lat, long float64) common.RecordData {
+		return common.RecordData{Position: common.Ptr(common.NewPosition(lat, long))}
+	}
+
+	t.Run("no positions", func(t *testing.T) {
+		result := RouteMap([]common.RecordData{{}, {}}, 0, 10, 5)
+		if result != "" {
+			t.Errorf("Expected empty map, Got:\n%s", result)
+		}
+	})
+
+	t.Run("marks current position", func(t *testing.T) {
+		records := []common.RecordData{
+			position(52.0, 13.0),
+			position(52.0, 13.1),
+			{}, // record without position
+			position(52.1, 13.1),
+		}
+		result := RouteMap(records, 2, 10, 5)
+		rows := strings.Split(result, "\n")
+		if len(rows) != 5 {
+			t.Fatalf("Expected 5 rows, Got: %d", len(rows))
+		}
+		if strings.Count(result, BulletPointBig) != 1 {
+			t.Errorf("Expected one marker, Got:\n%s", result)
+		}
+		// latest position before record #3 is at the east side of the bottom row
+		if !strings.HasSuffix(strings.TrimRight(rows[4], " "), BulletPointBig) {
+			t.Errorf("Expected marker at the end of last row, Got:\n%s", result)
+		}
+	})
+}
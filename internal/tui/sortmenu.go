@@ -0,0 +1,74 @@
+package tui
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+	"github.com/sectore/fit-activities-tui/internal/common"
+)
+
+// Handles keys of the sort menu
+func (m *Model) updateSortMenu(msg tea.KeyMsg) tea.Cmd {
+	field := common.SortFields[m.sortMenuIndex]
+	var keys common.SortKeys
+
+	switch msg.String() {
+	case "up", "k":
+		m.sortMenuIndex = max(m.sortMenuIndex-1, 0)
+	case "down", "j":
+		m.sortMenuIndex = min(m.sortMenuIndex+1, len(common.SortFields)-1)
+	case "enter":
+		keys = m.actsSort.WithPrimary(field)
+	case "tab":
+		keys = m.actsSort.WithSecondary(field)
+	case "x", "backspace":
+		keys = m.actsSort.Without(field)
+	case "esc", "s":
+		m.showSortMenu = false
+	case "ctrl+c":
+		return tea.Quit
+	}
+
+	// sort if keys have been changed,
+	// which is not possible while parsing
+	if keys != nil && !ActivitiesParsing(m.activities) {
+		m.actsSort = keys
+		return m.sortActs()
+	}
+	return nil
+}
+
+// `SortMenuView` renders all sort fields incl. their priority and direction.
+func SortMenuView(keys common.SortKeys, selected int) string {
+	rows := make([][]string, len(common.SortFields))
+	for index, field := range common.SortFields {
+		marker := ""
+		if index == selected {
+			marker = BulletPointBig
+		}
+		priority := ""
+		if keyIndex := keys.Index(field); keyIndex >= 0 {
+			arrow := arrowTop
+			if keys[keyIndex].Desc {
+				arrow = arrowDown
+			}
+			priority = fmt.Sprintf("%d. %s", keyIndex+1, arrow)
+		}
+		rows[index] = []string{marker, field.Label(), priority}
+	}
+
+	t := table.New().
+		Rows(rows...).
+		Border(lipgloss.Border{}).
+		StyleFunc(func(row, col int) lipgloss.Style {
+			style := lipgloss.NewStyle().PaddingRight(2)
+			if row == selected {
+				return style.Bold(true)
+			}
+			return style
+		})
+
+	return b("sort by") + br + br + fmt.Sprintf("%s", t)
+}
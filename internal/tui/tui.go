@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
@@ -12,36 +13,62 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/lipgloss/table"
+	"github.com/muktihari/fit/profile/filedef"
 	"github.com/sectore/fit-activities-tui/internal/asyncdata"
+	"github.com/sectore/fit-activities-tui/internal/cache"
 	"github.com/sectore/fit-activities-tui/internal/common"
+	"github.com/sectore/fit-activities-tui/internal/export"
 	"github.com/sectore/fit-activities-tui/internal/fit"
+	"github.com/sectore/fit-activities-tui/internal/query"
+	"github.com/sectore/fit-activities-tui/internal/watch"
 )
 
-type ActsSort int
-
-const (
-	TimeAsc ActsSort = iota
-	TimeDesc
-	DistanceAsc
-	DistanceDesc
-)
+// Default sort: latest activities first
+var DefaultActsSort = common.SortKeys{{Field: common.SortFieldTime, Desc: true}}
 
 type Model struct {
-	importFilePaths []string
-	importIndex     int
-	activities      common.Activities
-	errMsgs         []error
-	spinner         spinner.Model
-	list            list.Model
-	width           int
-	height          int
-	showMenu        bool
-	actsSort        ActsSort
+	importFiles []fit.ImportFile
+	// max. number of files parsed concurrently
+	jobs  int
+	cache *cache.Cache
+	watch *WatchOptions
+	// latest files of watched sources
+	watchSnapshot watch.Snapshot
+	// (re-)import of all files is in progress
+	importing bool
+	// activities of files changed while parsing them, their results are outdated
+	// and they are parsed again
+	outdated   map[*common.Activity]bool
+	activities common.Activities
+	errMsgs    []error
+	// latest info message (e.g. path of an exported file)
+	infoMsg string
+	// directory to export activities to
+	exportDir string
+	// positions inside of these zones are removed from exports
+	privacyZones common.PrivacyZones
+	spinner      spinner.Model
+	list         list.Model
+	width        int
+	height       int
+	showMenu     bool
+	showLaps     bool
+	showMap      bool
+	// totals recalculated by records (distance, elevation) next to the values of the device
+	showRecomputed bool
+	actsSort       common.SortKeys
+	// sort menu
+	showSortMenu  bool
+	sortMenuIndex int
 	// live data
 	showLiveData       bool
 	playLiveData       bool
 	liveDataSpeed      uint
 	liveDataLastUpdate time.Time
+	// crop: records marked (live data) to keep (-1: not marked)
+	cropAct   *common.Activity
+	cropStart int
+	cropEnd   int
 }
 
 const (
@@ -68,7 +95,8 @@ var (
 	leftContentStyle  = lipgloss.NewStyle().Width(30)
 	rightContentStyle = lipgloss.NewStyle().Padding(0, 2, 2, 0)
 	footerStyle       = lipgloss.NewStyle().Padding(0, 1)
-	errorStyle        = lipgloss.NewStyle().Foreground(lipgloss.Color("#F25D94"))
+	errorColor        = lipgloss.Color("#F25D94")
+	errorStyle        = lipgloss.NewStyle().Foreground(errorColor)
 	noColor           = lipgloss.NoColor{}
 	emptyStyle        = lipgloss.NewStyle()
 	br                = lipgloss.NewStyle().SetString("\n").String()
@@ -76,7 +104,29 @@ var (
 	b                 = lipgloss.NewStyle().Bold(true).Render
 )
 
-func InitialModel(filePaths []string) Model {
+// Options to configure the `Model`
+type Options struct {
+	// max. number of files parsed concurrently
+	Jobs int
+	// cache of parsed files (optional)
+	Cache *cache.Cache
+	// watches import sources for created, modified and deleted files (optional)
+	Watch *WatchOptions
+	// directory to export activities to (default: current directory)
+	ExportDir string
+	// positions inside of these zones are removed from exports (optional)
+	PrivacyZones common.PrivacyZones
+}
+
+// Options to watch import sources
+type WatchOptions struct {
+	Sources       []string
+	ImportOptions fit.ImportOptions
+	// time between two scans of all sources
+	Interval time.Duration
+}
+
+func InitialModel(files []fit.ImportFile, opts Options) Model {
 
 	s := spinner.New()
 	s.Spinner = spinner.MiniDot
@@ -96,6 +146,9 @@ func InitialModel(filePaths []string) Model {
 	keyMap.PrevPage.Unbind()
 	l.KeyMap = keyMap
 
+	// filter by query (e.g. "sport:cycling dist>50km") instead of fuzzy matching
+	l.Filter = QueryFilter
+
 	// styles for prompt needs to be passed to `FilterInput`
 	lfi := l.FilterInput
 	lfi.Prompt = "/"
@@ -125,31 +178,69 @@ func InitialModel(filePaths []string) Model {
 	l.SetShowTitle(true)
 	l.SetShowStatusBar(false)
 
+	// first snapshot of watched sources are all imported files,
+	// changes while importing are detected by the first scan
+	var watchSnapshot watch.Snapshot
+	if opts.Watch != nil {
+		watchSnapshot = watch.SnapshotOf(files)
+	}
+
 	return Model{
-		importFilePaths:    filePaths,
-		importIndex:        0,
+		importFiles:        files,
+		watchSnapshot:      watchSnapshot,
+		outdated:           map[*common.Activity]bool{},
+		jobs:               max(opts.Jobs, 1),
+		cache:              opts.Cache,
+		watch:              opts.Watch,
+		exportDir:          opts.ExportDir,
+		privacyZones:       opts.PrivacyZones,
 		activities:         common.Activities{},
 		spinner:            s,
 		list:               l,
 		width:              0,
 		height:             0,
 		showMenu:           false,
-		actsSort:           TimeDesc,
+		showLaps:           false,
+		showMap:            false,
+		showRecomputed:     false,
+		actsSort:           DefaultActsSort,
+		showSortMenu:       false,
+		sortMenuIndex:      0,
 		showLiveData:       false,
 		playLiveData:       false,
 		liveDataSpeed:      1,
 		liveDataLastUpdate: time.Now(),
+		cropStart:          -1,
+		cropEnd:            -1,
 	}
 }
 
 func (m Model) Init() tea.Cmd {
-	return tea.Batch(m.spinner.Tick, parseFilesCmd(), tick())
+	cmds := []tea.Cmd{m.spinner.Tick, parseFilesCmd(), tick()}
+	if m.watch != nil {
+		cmds = append(cmds, watchCmd(*m.watch, m.watchSnapshot))
+	}
+	return tea.Batch(cmds...)
 }
 
 func (m *Model) sortActs() tea.Cmd {
+	return m.setItems(m.list.Items())
+}
 
-	items := SortItems(m.list.Items(), m.actsSort)
+// Sets sorted items to the list.
+// Current filter and selected item will be kept.
+// While importing all files, the selection keeps its index (e.g. latest activity on top)
+// instead of following the item parsed first.
+func (m *Model) setItems(items []list.Item) tea.Cmd {
 
+	items = SortItems(items, m.actsSort)
+
+	// While typing a filter, the list does filter all items by itself
+	if m.list.SettingFilter() {
+		return m.list.SetItems(items)
+	}
+
+	selected := m.list.SelectedItem()
 	// Note: `SetItems` resets the filter internally.
 	// That's why we need to remember filter text BEFORE ...
 	filterText := m.list.FilterInput.Value()
@@ -160,6 +251,13 @@ func (m *Model) sortActs() tea.Cmd {
 		m.list.SetFilterText(filterText)
 	}
 
+	// select previous item again (its index might be changed)
+	if selected != nil && !m.importing {
+		if index := slices.Index(m.list.VisibleItems(), selected); index >= 0 {
+			m.list.Select(index)
+		}
+	}
+
 	return cmd
 }
 
@@ -181,6 +279,13 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case tea.KeyMsg:
 		log.Printf("key %s", msg.String())
+
+		// sort menu handles all keys while it's open
+		if m.showSortMenu && !m.list.SettingFilter() {
+			cmd := m.updateSortMenu(msg)
+			return m, cmd
+		}
+
 		switch msg.String() {
 
 		case "r":
@@ -191,6 +296,128 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					act.ResetRecordIndex()
 				}
 			}
+		case "R":
+			// retry parsing of SELECTED item, if it has been failed
+			if !m.list.SettingFilter() {
+				item := m.list.SelectedItem()
+				if act, ok := item.(*common.Activity); ok {
+					if _, ok := asyncdata.Failure(act.Data); ok {
+						act.Data = asyncdata.NewLoading[error, common.ActivityData](nil)
+						cmds = append(cmds, retryParseFileCmd(act, m.cache))
+					}
+				}
+			}
+		case "e", "c":
+			// export SELECTED item
+			if !m.list.SettingFilter() {
+				format := export.FormatGPX
+				if msg.String() == "c" {
+					format = export.FormatCSV
+				}
+				item := m.list.SelectedItem()
+				if act, ok := item.(*common.Activity); ok {
+					if data, ok := asyncdata.Success(act.Data); ok {
+						cmds = append(cmds, exportCmd(m.exportDir, format, act.Path, *data, m.privacyZones))
+					}
+				}
+			}
+		case "C":
+			// export summaries of all VISIBLE (filtered) items
+			if !m.list.SettingFilter() {
+				var acts []*common.Activity
+				for _, item := range m.list.VisibleItems() {
+					if act, ok := item.(*common.Activity); ok {
+						acts = append(acts, act)
+					}
+				}
+				cmds = append(cmds, exportSummaryCmd(m.exportDir, acts))
+			}
+		case "[", "]":
+			// mark current record of SELECTED item as start or end to crop
+			if m.showLiveData && !m.list.SettingFilter() {
+				item := m.list.SelectedItem()
+				if act, ok := item.(*common.Activity); ok {
+					if _, ok := asyncdata.Success(act.Data); ok {
+						// marks of another activity are dropped
+						if m.cropAct != act {
+							m.cropAct = act
+							m.cropStart, m.cropEnd = -1, -1
+						}
+						if msg.String() == "[" {
+							m.cropStart = act.RecordIndex()
+						} else {
+							m.cropEnd = act.RecordIndex()
+						}
+					}
+				}
+			}
+		case "w":
+			// write marked records of SELECTED item into a new FIT file
+			if m.showLiveData && !m.list.SettingFilter() {
+				item := m.list.SelectedItem()
+				if act, ok := item.(*common.Activity); ok && act == m.cropAct {
+					start, end := m.cropStart, m.cropEnd
+					if start < 0 {
+						start = 0
+					}
+					if data, ok := asyncdata.Success(act.Data); ok && end < 0 {
+						end = data.NoRecords() - 1
+					}
+					// end might be marked before start
+					if start > end {
+						start, end = end, start
+					}
+					if start == end {
+						m.infoMsg = "mark at least 2 records to crop"
+					} else {
+						cmds = append(cmds, cropCmd(m.exportDir, act.Path, start, end, m.privacyZones))
+					}
+				}
+			}
+		case "|", "P":
+			// split SELECTED item at current record or at its longest pause
+			if m.showLiveData && !m.list.SettingFilter() {
+				item := m.list.SelectedItem()
+				if act, ok := item.(*common.Activity); ok {
+					if _, ok := asyncdata.Success(act.Data); ok {
+						at := act.RecordIndex()
+						if msg.String() == "P" {
+							at = -1
+						}
+						cmds = append(cmds, splitCmd(m.exportDir, act.Path, at, m.privacyZones))
+					}
+				}
+			}
+		case "x":
+			// mark SELECTED item to merge it with other marked items
+			if !m.list.SettingFilter() {
+				item := m.list.SelectedItem()
+				if act, ok := item.(*common.Activity); ok {
+					if _, ok := asyncdata.Success(act.Data); ok {
+						act.ToggleMarked()
+					}
+				}
+			}
+		case "M":
+			// merge ALL marked items into a new FIT file
+			if !m.list.SettingFilter() {
+				var paths []string
+				for _, item := range m.list.Items() {
+					if act, ok := item.(*common.Activity); ok && act.Marked() {
+						paths = append(paths, act.Path)
+					}
+				}
+				if len(paths) < 2 {
+					m.infoMsg = "mark at least 2 activities to merge"
+					break
+				}
+				for _, item := range m.list.Items() {
+					if act, ok := item.(*common.Activity); ok && act.Marked() {
+						act.ToggleMarked()
+					}
+				}
+				cmds = append(cmds, mergeCmd(m.exportDir, paths, m.privacyZones))
+			}
 		case "ctrl+r":
 			// reset `selectedRecordIndex` of ALL items
 			if !m.list.SettingFilter() {
@@ -206,8 +433,9 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if !m.list.SettingFilter() {
 				// reset activities
 				m.activities = common.Activities{}
-				// reset import index
-				m.importIndex = 0
+				// reset errors
+				m.errMsgs = nil
+				m.infoMsg = ""
 				// reset list
 				m.list.ResetSelected()
 				m.list.ResetFilter()
@@ -222,6 +450,25 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		case "l":
 			m.showLiveData = !m.showLiveData
+		case "a":
+			if !m.list.SettingFilter() {
+				m.showLaps = !m.showLaps
+				// laps and map share the same space
+				if m.showLaps {
+					m.showMap = false
+				}
+			}
+		case "p":
+			if !m.list.SettingFilter() {
+				m.showMap = !m.showMap
+				if m.showMap {
+					m.showLaps = false
+				}
+			}
+		case "t":
+			if !m.list.SettingFilter() {
+				m.showRecomputed = !m.showRecomputed
+			}
 		case " ":
 			if m.showLiveData {
 				m.playLiveData = !m.playLiveData
@@ -308,24 +555,21 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		case "ctrl+d":
 			if !ActivitiesParsing(m.activities) {
-				if m.actsSort != DistanceDesc {
-					m.actsSort = DistanceDesc
-				} else {
-					m.actsSort = DistanceAsc
-				}
+				m.actsSort = m.actsSort.WithPrimary(common.SortFieldDistance)
 				cmd := m.sortActs()
 				cmds = append(cmds, cmd)
 			}
 		case "ctrl+t":
 			if !ActivitiesParsing(m.activities) {
-				if m.actsSort != TimeDesc {
-					m.actsSort = TimeDesc
-				} else {
-					m.actsSort = TimeAsc
-				}
+				m.actsSort = m.actsSort.WithPrimary(common.SortFieldTime)
 				cmd := m.sortActs()
 				cmds = append(cmds, cmd)
 			}
+		case "s":
+			if !m.list.SettingFilter() {
+				m.showSortMenu = true
+				m.sortMenuIndex = 0
+			}
 		case "q":
 			if !m.list.SettingFilter() {
 				return m, tea.Quit
@@ -334,41 +578,108 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case parseFilesMsg:
 		// list of `NotAsked` activities
-		paths := m.importFilePaths
-		activities := make([]*common.Activity, len(paths))
-		for i, path := range paths {
+		files := m.importFiles
+		// sources are relevant to show for more than one source only
+		showSources := HasMultipleSources(files)
+		activities := make([]*common.Activity, len(files))
+		for i, file := range files {
 			activities[i] = &common.Activity{
-				Path: path,
+				Path: file.Path,
 				Data: asyncdata.NewNotAsked[error, common.ActivityData](),
 			}
+			if showSources {
+				activities[i].Source = file.Source
+			}
 		}
 		m.activities = activities
-		// parse first Activity
-		firstAct := m.activities[0]
-		firstAct.Data = asyncdata.NewLoading[error, common.ActivityData](nil)
-		cmds = append(cmds, parseFileCmd(firstAct))
+		m.importing = true
+		clear(m.outdated)
+		// parse first activities concurrently (limited by number of `jobs`)
+		for range m.jobs {
+			cmd := m.parseNextFileCmd()
+			if cmd == nil {
+				break
+			}
+			cmds = append(cmds, cmd)
+		}
 
 	case parseFileResultMsg:
-		i := m.importIndex
-		*m.activities[i] = *msg.Activity
+		// ignore results of removed activities (e.g. after reloading all files)
+		if !slices.Contains(m.activities, msg.act) {
+			break
+		}
+		// file has been changed while parsing it
+		if m.outdated[msg.act] {
+			if cmd := m.reparseFileCmd(msg.act); cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+			break
+		}
+		m.applyParseResult(msg)
+		if err, ok := asyncdata.Failure(msg.data); ok {
+			cmds = append(cmds, errCmd(*err))
+		}
 		// add new item to current items
-		items := append(m.list.Items(), m.activities[i])
-		// sort list
-		items = SortItems(items, m.actsSort)
+		// (an item of a re-parsed file, e.g. in watch mode, is already listed)
+		items := m.list.Items()
+		if !slices.Contains(items, list.Item(msg.act)) {
+			items = append(items, msg.act)
+		}
 		// set sorted items to list
-		cmd := m.list.SetItems(items)
+		cmd := m.setItems(items)
 		cmds = append(cmds, cmd)
+		// import is done, if all activities are parsed
+		m.importing = m.importing && slices.ContainsFunc(m.activities, func(act *common.Activity) bool {
+			_, _, loading := asyncdata.Loading(act.Data)
+			return loading || asyncdata.NotAsked(act.Data)
+		})
+
+		// a job is free now to parse next activity
+		if cmd := m.parseNextFileCmd(); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
 
-		if i < len(m.activities)-1 {
-			m.importIndex++
-			act := m.activities[m.importIndex]
-			act.Data = asyncdata.NewLoading[error, common.ActivityData](nil)
-			cmds = append(cmds, parseFileCmd(act))
+	case retryParseFileResultMsg:
+		if !slices.Contains(m.activities, msg.act) {
+			break
+		}
+		if m.outdated[msg.act] {
+			if cmd := m.reparseFileCmd(msg.act); cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+			break
 		}
+		m.applyParseResult(parseFileResultMsg(msg))
+		if err, ok := asyncdata.Failure(msg.data); ok {
+			cmds = append(cmds, errCmd(*err))
+		}
+		// sort again to consider updated data
+		cmd := m.sortActs()
+		cmds = append(cmds, cmd)
+
+	case watchMsg:
+		m.watchSnapshot = msg.snapshot
+		cmds = append(cmds, m.applyWatchEvents(msg.events)...)
+		cmds = append(cmds, watchCmd(*m.watch, m.watchSnapshot))
 
 	case errMsg:
 		m.errMsgs = append(m.errMsgs, msg)
 
+	case exportedMsg:
+		m.infoMsg = fmt.Sprintf("exported to %s", msg.path)
+
+	case savedMsg:
+		m.infoMsg = fmt.Sprintf("saved %s", strings.Join(msg.paths, ", "))
+		m.cropAct = nil
+		m.cropStart, m.cropEnd = -1, -1
+		// add new files to list
+		var events []watch.Event
+		for _, path := range msg.paths {
+			file := fit.ImportFile{Path: path, Source: m.exportDir}
+			events = append(events, watch.Event{Kind: watch.Created, File: file})
+		}
+		cmds = append(cmds, m.applyWatchEvents(events)...)
+
 	case tickMsg:
 		now := time.Now()
 
@@ -445,6 +756,11 @@ func (m Model) RightContentView() string {
 			{"total time", ActivitiesTotalDuration(visibleItems).Format()},
 			{"total distance", ActivitiesTotalDistances(visibleItems).Format()},
 		}
+		if noFailures := ActivitiesFailures(visibleItems); noFailures > 0 {
+			sumRows = append(sumRows,
+				[]string{"failed", errorStyle.Render(fmt.Sprintf("%s %d", common.FailureMarker, noFailures))},
+			)
+		}
 		sumTable := table.New().
 			Rows(sumRows...).
 			Border(lipgloss.Border{}).
@@ -476,6 +792,24 @@ func (m Model) RightContentView() string {
 				Reverse(true).
 				Render("live")
 		}
+		if m.showLaps {
+			extraLabel += lipgloss.NewStyle().
+				PaddingLeft(1).PaddingRight(1).
+				Bold(true).
+				Render("laps")
+		}
+		if m.showMap {
+			extraLabel += lipgloss.NewStyle().
+				PaddingLeft(1).PaddingRight(1).
+				Bold(true).
+				Render("map")
+		}
+		if m.showRecomputed && !m.showLiveData {
+			extraLabel += lipgloss.NewStyle().
+				PaddingLeft(1).PaddingRight(1).
+				Bold(true).
+				Render("recomputed")
+		}
 
 		detailsView += lipgloss.NewStyle().
 			Bold(true).
@@ -500,6 +834,8 @@ func (m Model) RightContentView() string {
 		if act, ok := item.(*common.Activity); ok {
 
 			var rows [][]string
+			// row to separate first rows (e.g. distance) from bars by a margin
+			firstBlockRow := 2
 			if ad, ok := asyncdata.Success(act.Data); ok {
 
 				currentRecord := ad.Records[act.RecordIndex()]
@@ -512,6 +848,8 @@ func (m Model) RightContentView() string {
 				b1 := BarEmptyHalf
 				b2 := BarFullHalf
 
+				speedLabel := SpeedLabel(ad.Sport)
+
 				if m.showLiveData {
 					timeTxt := i(common.NoDataText)
 					if currentRecord.Time != nil {
@@ -555,8 +893,8 @@ func (m Model) RightContentView() string {
 					speedBarTxt := ""
 					speedBar := HorizontalBar(0, b1, 0, b0, BarWidth)
 					if ad.Speed.Max != nil && currentRecord.Speed != nil {
-						speedTxt = col1(common.NewSpeed(0).Format()) + col2("max "+ad.Speed.Max.Format())
-						speedBarTxt = currentRecord.Speed.Format()
+						speedTxt = col1(ad.Sport.FormatSpeed(common.NewSpeed(0))) + col2("max "+ad.Sport.FormatSpeed(*ad.Speed.Max))
+						speedBarTxt = ad.Sport.FormatSpeed(*currentRecord.Speed)
 						speedBar = HorizontalBar(
 							float64(currentRecord.Speed.Value),
 							b1,
@@ -626,14 +964,50 @@ func (m Model) RightContentView() string {
 							BarWidth)
 					}
 
+					cadenceTxt := col1(i(common.NoDataText))
+					cadenceBarTxt := ""
+					cadenceBar := HorizontalBar(0, b1, 0, b0, BarWidth)
+					if ad.Cadence.Min != nil && ad.Cadence.Max != nil && currentRecord.Cadence != nil {
+						cadenceTxt = col1("min "+ad.Cadence.Min.Format()) + col2("max "+ad.Cadence.Max.Format())
+						cadenceBarTxt = currentRecord.Cadence.Format()
+						cadenceBar = HorizontalBarWithRange(
+							float64(currentRecord.Cadence.Value),
+							b1,
+							float64(ad.Cadence.Min.Value),
+							float64(ad.Cadence.Max.Value),
+							b0,
+							BarWidth)
+					}
+
+					powerTxt := col1(i(common.NoDataText))
+					powerBarTxt := ""
+					powerBar := HorizontalBar(0, b1, 0, b0, BarWidth)
+					if ad.Power.Max != nil && currentRecord.Power != nil {
+						powerTxt = col1(common.NewPower(0).Format()) + col2("max "+ad.Power.Max.Format())
+						powerBarTxt = currentRecord.Power.Format()
+						powerBar = HorizontalBar(
+							float64(currentRecord.Power.Value),
+							b1,
+							float64(ad.Power.Max.Value),
+							b0,
+							BarWidth)
+					}
+
 					rows = [][]string{
 						{b("time"), timeTxt},
 						{b("distance"), distanceTxt},
 						{distanceBarTxt, distanceBar},
 						{b("duration"), durationTxt},
 						{durationBarTxt, durationBar},
-						{b("speed"), speedTxt},
-						{speedBarTxt, speedBar},
+					}
+					// no speed for strength workouts
+					if !ad.Sport.IsStrength() {
+						rows = append(rows,
+							[]string{b(speedLabel), speedTxt},
+							[]string{speedBarTxt, speedBar},
+						)
+					}
+					rows = append(rows, [][]string{
 						{b("altitude"), altitudeTxt},
 						{altitudeBarTxt, altitudeBar},
 						{b("temperature"), temperatureTxt},
@@ -642,25 +1016,22 @@ func (m Model) RightContentView() string {
 						{gpsBarTxt, gpsBar},
 						{b("♥ rate"), heartrateTxt},
 						{heartrateBarTxt, heartrateBar},
+						{b("cadence"), cadenceTxt},
+						{cadenceBarTxt, cadenceBar},
+						{b("power"), powerTxt},
+						{powerBarTxt, powerBar},
 						{b("sessions"), noSessionsText},
 						{b("record"), fmt.Sprint(act.RecordIndex()+1) + " of " + noRecordsText},
-					}
+					}...)
 				} else {
-					dateTxt := i(common.NoDataText)
-					if ad.StartTime() != nil && ad.FinishTime() != nil {
-						dateTxt = ad.StartTime().Format() + "-" + ad.FinishTime().FormatHhMm()
-
+					dateTxt := DateText(*ad)
+					if dateTxt == common.NoDataText {
+						dateTxt = i(dateTxt)
 					}
 					durationTxt := i(common.NoDataText)
 					durationBar := HorizontalBar(0, b1, 0, b0, BarWidth)
-					if ad.Duration.Active != nil && ad.Duration.Pause != nil {
-						durationTxt = col1(ad.Duration.Active.Format())
-						pauseTxt := "pause " + ad.Duration.Pause.Format()
-						if ad.Duration.Pause.Value <= 0 {
-							pauseTxt = "(no pause)"
-						}
-
-						durationTxt += col2(pauseTxt)
+					if activeTxt, pauseTxt, ok := ActiveTexts(*ad); ok {
+						durationTxt = col1(activeTxt) + col2(pauseTxt)
 						durationBar = HorizontalStackedBar(
 							float64(ad.Duration.Active.Value),
 							b1,
@@ -671,8 +1042,8 @@ func (m Model) RightContentView() string {
 
 					speedTxt := i(common.NoDataText)
 					speedBar := HorizontalBar(0, b1, 0, b0, BarWidth)
-					if ad.Speed.Avg != nil && ad.Speed.Max != nil {
-						speedTxt = col1("⌀ "+ad.Speed.Avg.Format()) + col2("max "+ad.Speed.Max.Format())
+					if avgTxt, maxTxt, ok := SpeedTexts(*ad); ok {
+						speedTxt = col1(avgTxt) + col2(maxTxt)
 						speedBar = HorizontalBar(
 							float64(ad.Speed.Avg.Value),
 							b1,
@@ -684,9 +1055,8 @@ func (m Model) RightContentView() string {
 
 					elevationTxt := i(common.NoDataText)
 					elevationBar := HorizontalBar(0, b1, 0, b0, BarWidth)
-					if ad.Elevation.Ascents != nil && ad.Elevation.Descents != nil {
-						elevationTxt = col1(arrowTop+" "+ad.Elevation.Ascents.Format()) +
-							col2(arrowDown+" "+ad.Elevation.Descents.Format())
+					if ascentsTxt, descentsTxt, ok := ElevationTexts(ad.Elevation); ok {
+						elevationTxt = col1(ascentsTxt) + col2(descentsTxt)
 						elevationBar = HorizontalStackedBar(
 							float64(ad.Elevation.Ascents.Value),
 							b1,
@@ -697,9 +1067,8 @@ func (m Model) RightContentView() string {
 
 					temperatureTxt := i(common.NoDataText)
 					temperatureBar := HorizontalBar(0, b1, 0, b0, BarWidth)
-					if ad.Temperature.Avg != nil && ad.Temperature.Max != nil {
-						temperatureTxt = col1("⌀ "+ad.Temperature.Avg.Format()) +
-							col2("max "+ad.Temperature.Max.Format())
+					if avgTxt, maxTxt, ok := TemperatureTexts(*ad); ok {
+						temperatureTxt = col1(avgTxt) + col2(maxTxt)
 						temperatureBar = HorizontalBar(
 							float64(ad.Temperature.Avg.Value),
 							b1,
@@ -710,9 +1079,8 @@ func (m Model) RightContentView() string {
 
 					gpsTxt := i(common.NoDataText)
 					gpsBar := HorizontalBar(0, b1, 0, b0, BarWidth)
-					if ad.GpsAccuracy.Avg != nil && ad.GpsAccuracy.Max != nil {
-						gpsTxt = col1("⌀ "+ad.GpsAccuracy.Avg.Format()) +
-							col2("worse "+ad.GpsAccuracy.Max.Format())
+					if avgTxt, worstTxt, ok := GpsAccuracyTexts(*ad); ok {
+						gpsTxt = col1(avgTxt) + col2(worstTxt)
 						gpsBar = HorizontalBar(
 							float64(ad.GpsAccuracy.Avg.Value),
 							b1,
@@ -723,9 +1091,8 @@ func (m Model) RightContentView() string {
 
 					heartrateTxt := i(common.NoDataText)
 					heartrateBar := HorizontalBar(0, b1, 0, b0, BarWidth)
-					if ad.Heartrate.Avg != nil && ad.Heartrate.Max != nil {
-						heartrateTxt = col1("⌀ "+ad.Heartrate.Avg.Format()) +
-							col2("max "+ad.Heartrate.Max.Format())
+					if avgTxt, maxTxt, ok := HeartrateTexts(*ad); ok {
+						heartrateTxt = col1(avgTxt) + col2(maxTxt)
 						heartrateBar = HorizontalBar(
 							float64(ad.Heartrate.Avg.Value),
 							b1,
@@ -734,30 +1101,102 @@ func (m Model) RightContentView() string {
 							BarWidth)
 					}
 
+					cadenceTxt := i(common.NoDataText)
+					cadenceBar := HorizontalBar(0, b1, 0, b0, BarWidth)
+					if avgTxt, maxTxt, ok := CadenceTexts(*ad); ok {
+						cadenceTxt = col1(avgTxt) + col2(maxTxt)
+						cadenceBar = HorizontalBar(
+							float64(ad.Cadence.Avg.Value),
+							b1,
+							float64(ad.Cadence.Max.Value),
+							b0,
+							BarWidth)
+					}
+
+					powerTxt := i(common.NoDataText)
+					powerBar := HorizontalBar(0, b1, 0, b0, BarWidth)
+					if avgTxt, maxTxt, ok := PowerTexts(*ad); ok {
+						powerTxt = col1(avgTxt) + col2(maxTxt)
+						powerBar = HorizontalBar(
+							float64(ad.Power.Avg.Value),
+							b1,
+							float64(ad.Power.Max.Value),
+							b0,
+							BarWidth)
+					}
+
 					rows = [][]string{
 						{b("date"), dateTxt},
+						{b("sport"), ad.Sport.Format()},
 						{b("distance"), act.TotalDistance().Format()},
-						{b("active"), durationTxt},
-						{"", durationBar},
-						{b("speed"), speedTxt},
-						{"", speedBar},
-						{b("elevation"), elevationTxt},
-						{"", elevationBar},
+					}
+					// recomputed totals next to the values of the device
+					var recomputed common.RecomputedTotals
+					if m.showRecomputed {
+						recomputed = common.RecomputeTotals(ad.Records, common.DefaultRecomputeOptions())
+						distanceTxt := i(common.NoDataText)
+						if recomputed.Distance != nil {
+							distanceTxt = recomputed.Distance.Format()
+						}
+						rows = append(rows, []string{b("recomputed"), distanceTxt})
+						firstBlockRow = 3
+					}
+					rows = append(rows,
+						[]string{b("active"), durationTxt},
+						[]string{"", durationBar},
+					)
+					// no speed for strength workouts
+					if !ad.Sport.IsStrength() {
+						rows = append(rows,
+							[]string{b(speedLabel), speedTxt},
+							[]string{"", speedBar},
+						)
+					}
+					rows = append(rows,
+						[]string{b("elevation"), elevationTxt},
+						[]string{"", elevationBar},
+					)
+					if m.showRecomputed {
+						recomputedElevationTxt := i(common.NoDataText)
+						if ascentsTxt, descentsTxt, ok := ElevationTexts(recomputed.Elevation); ok {
+							recomputedElevationTxt = col1(ascentsTxt) + col2(descentsTxt)
+						}
+						rows = append(rows, []string{b("recomputed"), recomputedElevationTxt})
+					}
+					rows = append(rows, [][]string{
 						{b("temperature"), temperatureTxt},
 						{"", temperatureBar},
 						{b("gps accuracy"), gpsTxt},
 						{"", gpsBar},
 						{b("♥ rate"), heartrateTxt},
 						{"", heartrateBar},
+						{b("cadence"), cadenceTxt},
+						{"", cadenceBar},
+						{b("power"), powerTxt},
+						{"", powerBar},
 						{b("sessions"), noSessionsText},
 						{b("records"), noRecordsText},
-					}
+					}...)
 				}
 
 			}
+			// last bar row (right before "sessions" and "records")
+			lastBarRow := len(rows) - 3
+			if err, ok := asyncdata.Failure(act.Data); ok && *err != nil {
+				rows = [][]string{
+					{b("error"), errorStyle.Width(BarWidth).Render((*err).Error())},
+					{"", i("[R]etry parsing")},
+				}
+			}
+			if _, ok := asyncdata.Success(act.Data); ok && m.showLiveData && m.cropAct == act {
+				rows = append(rows, []string{"crop", m.cropRangeText()})
+			}
 			rows = append(rows,
 				[]string{"file", filepath.Base(act.Path)},
 			)
+			if act.Source != "" {
+				rows = append(rows, []string{"source", act.Source})
+			}
 			table := table.New().
 				Rows(rows...).
 				Border(lipgloss.Border{}).
@@ -765,15 +1204,21 @@ func (m Model) RightContentView() string {
 					switch {
 					case col == 0:
 						return lipgloss.NewStyle().PaddingRight(2)
-					case !m.showLiveData && (row == 1 || row == 13):
-						return lipgloss.NewStyle().MarginBottom(2)
-					case m.showLiveData && (row == 2 || row == 14):
+					case row == firstBlockRow || row == lastBarRow:
 						return lipgloss.NewStyle().MarginBottom(2)
 					default:
 						return lipgloss.NewStyle().PaddingRight(1)
 					}
 				})
-			detailsView += fmt.Sprintf("%s", table)
+			if m.showSortMenu {
+				detailsView += SortMenuView(m.actsSort, m.sortMenuIndex)
+			} else if ad, ok := asyncdata.Success(act.Data); ok && m.showLaps {
+				detailsView += LapsView(*ad, ad.Records[act.RecordIndex()])
+			} else if ok && m.showMap {
+				detailsView += MapView(*ad, act.RecordIndex())
+			} else {
+				detailsView += fmt.Sprintf("%s", table)
+			}
 		}
 
 	}
@@ -786,6 +1231,23 @@ func (m Model) RightContentView() string {
 	)
 }
 
+// Range of records marked to crop, e.g. "records 3-last"
+func (m Model) cropRangeText() string {
+	cropStart, cropEnd := m.cropStart, m.cropEnd
+	// end might be marked before start
+	if cropStart >= 0 && cropEnd >= 0 && cropStart > cropEnd {
+		cropStart, cropEnd = cropEnd, cropStart
+	}
+	start, end := "first", "last"
+	if cropStart >= 0 {
+		start = fmt.Sprint(cropStart + 1)
+	}
+	if cropEnd >= 0 {
+		end = fmt.Sprint(cropEnd + 1)
+	}
+	return fmt.Sprintf("records %s-%s", start, end)
+}
+
 func (m Model) LeftContentView() string {
 
 	noVisibleActs := len(m.list.VisibleItems())
@@ -807,18 +1269,15 @@ func (m Model) LeftContentView() string {
 
 	view := m.list.View()
 
-	sortLabel := "sorted by "
-	switch m.actsSort {
-	case DistanceAsc:
-		sortLabel += "dist. " + arrowTop
-	case DistanceDesc:
-		sortLabel += "dist. " + arrowDown
-	case TimeAsc:
-		sortLabel += "time " + arrowTop
-	case TimeDesc:
-		sortLabel += "time " + arrowDown
+	// invalid filter query
+	if filterText := m.list.FilterInput.Value(); filterText != "" {
+		if _, err := query.Parse(filterText); err != nil {
+			view += br + errorStyle.PaddingLeft(2).Width(leftContentStyle.GetWidth()).Render(err.Error())
+		}
 	}
 
+	sortLabel := "sorted by " + SortKeysLabel(m.actsSort)
+
 	// empty label for a single item
 	if len(m.list.VisibleItems()) <= 1 {
 		sortLabel = " "
@@ -841,6 +1300,25 @@ func (m Model) footerView() string {
 	menu := fmt.Sprintf("[m]enu %s", symbol)
 	line := strings.Repeat("─", max(0, m.width-len(menu)-1))
 	view := fmt.Sprintf("%s %s", menu, line)
+	// latest info
+	if m.infoMsg != "" {
+		view = lipgloss.NewStyle().
+			MaxWidth(max(m.width-2, 0)).
+			Render(m.infoMsg) +
+			br + view
+	}
+	// latest error
+	if len(m.errMsgs) > 0 {
+		last := m.errMsgs[len(m.errMsgs)-1]
+		errTxt := fmt.Sprintf("%s %s", common.FailureMarker, last.Error())
+		if noErrs := len(m.errMsgs); noErrs > 1 {
+			errTxt += fmt.Sprintf(" (+%d more)", noErrs-1)
+		}
+		view = errorStyle.
+			MaxWidth(max(m.width-2, 0)).
+			Render(errTxt) +
+			br + view
+	}
 	if m.showMenu {
 		filterCol2 := "[/]start"
 		if m.list.SettingFilter() {
@@ -883,11 +1361,60 @@ func (m Model) footerView() string {
 			liveDataTxt += col("[^r]eset all")
 		}
 
-		sortTxt := col("[^t]ime") + col("[^d]uration")
+		cropTxt := col("[l]ive data first")
+		if m.showLiveData {
+			cropTxt = col("[[]start") + col("[]]end")
+			if item := m.list.SelectedItem(); item != nil && item == list.Item(m.cropAct) {
+				cropTxt += col("[w]rite new file")
+			}
+		}
+
+		splitTxt := col("[l]ive data first")
+		if m.showLiveData {
+			splitTxt = col("[|]at current record") + col("[P]at longest pause")
+		}
+
+		mergeTxt := col("[x]mark")
+		noMarked := 0
+		for _, item := range m.list.Items() {
+			if act, ok := item.(*common.Activity); ok && act.Marked() {
+				noMarked += 1
+			}
+		}
+		if noMarked > 1 {
+			mergeTxt += col(fmt.Sprintf("[M]erge %d marked", noMarked))
+		}
+
+		lapsTxt := col("[a]show")
+		if m.showLaps {
+			lapsTxt = col("[a]hide")
+		}
+
+		exportTxt := col("[e]gpx") + col("[c]sv") + col("[C]sv summary (all listed)")
+
+		mapTxt := col("[p]show")
+		if m.showMap {
+			mapTxt = col("[p]hide")
+		}
+
+		recomputedTxt := col("[t]show recomputed")
+		if m.showRecomputed {
+			recomputedTxt = col("[t]hide recomputed")
+		}
+
+		sortTxt := col("[^t]ime") + col("[^d]istance") + col("[s]more")
+		if m.showSortMenu {
+			sortTxt = col("["+arrowTop+arrowDown+"]select") +
+				col("[ENTER]sort by") +
+				col("[TAB]then by") +
+				col("[x]remove") +
+				col("[ESC]close")
+		}
 
 		listTxt := col("["+arrowTop+"]up") +
 			col("["+arrowDown+"]down") +
-			col("[g]first", "[G]last")
+			col("[g]first", "[G]last") +
+			col("[R]etry failed")
 
 		filterTxt := col(filterCol2) + col(filterCol3)
 
@@ -896,6 +1423,13 @@ func (m Model) footerView() string {
 			{"sort", sortTxt},
 			{"filter", filterTxt},
 			{"live data", liveDataTxt},
+			{"laps", lapsTxt},
+			{"map", mapTxt},
+			{"totals", recomputedTxt},
+			{"export", exportTxt},
+			{"crop", cropTxt},
+			{"split", splitTxt},
+			{"merge", mergeTxt},
 		}
 		table := table.New().
 			Rows(rows...).
@@ -935,34 +1469,273 @@ func (m Model) View() string {
 
 type (
 	parseFilesMsg      struct{}
-	parseFileResultMsg struct{ *common.Activity }
-	errMsg             struct{ err error }
+	parseFileResultMsg struct {
+		act  *common.Activity
+		data common.ActivityAD
+	}
+	retryParseFileResultMsg parseFileResultMsg
+	exportedMsg             struct{ path string }
+	savedMsg                struct{ paths []string }
+	watchMsg                struct {
+		snapshot watch.Snapshot
+		events   []watch.Event
+	}
+	errMsg struct{ err error }
 )
 
 func (e errMsg) Error() string { return e.err.Error() }
 
+func errCmd(err error) tea.Cmd {
+	return func() tea.Msg {
+		return errMsg{err}
+	}
+}
+
 func parseFilesCmd() tea.Cmd {
 	return func() tea.Msg {
 		return parseFilesMsg{}
 	}
 }
 
-func parseFileCmd(act *common.Activity) tea.Cmd {
+// Starts parsing of next `NotAsked` activity.
+// Returns `nil` if all activities are parsed or in progress.
+func (m *Model) parseNextFileCmd() tea.Cmd {
+	for _, act := range m.activities {
+		if asyncdata.NotAsked(act.Data) {
+			act.Data = asyncdata.NewLoading[error, common.ActivityData](nil)
+			return parseFileCmd(act, m.cache)
+		}
+	}
+	return nil
+}
+
+// Parses a file. Note: Each `tea.Cmd` runs in its own goroutine,
+// the result is applied to the activity in `Update` only.
+func parseFileCmd(act *common.Activity, c *cache.Cache) tea.Cmd {
 	return func() tea.Msg {
-		// channel to send result msg
-		resultCh := make(chan tea.Msg, 1)
-		// goroutine to do the parsing
-		go func() {
-			data, err := fit.ParseFile(act.Path)
+		data, err := ParseFile(act.Path, c)
+		if err != nil {
+			return parseFileResultMsg{act, asyncdata.NewFailure[error, common.ActivityData](err)}
+		}
+		return parseFileResultMsg{act, asyncdata.NewSuccess[error](*data)}
+	}
+}
+
+// Exports an activity into a new file of given directory
+func exportCmd(dir, format, path string, data common.ActivityData, zones common.PrivacyZones) tea.Cmd {
+	return func() tea.Msg {
+		file, err := export.ToFile(dir, format, path, data, zones)
+		if err != nil {
+			return errMsg{err}
+		}
+		return exportedMsg{file}
+	}
+}
+
+// Writes records `from`-`to` of an activity into a new FIT file of given directory.
+// The original file is never changed.
+func cropCmd(dir, path string, from, to int, zones common.PrivacyZones) tea.Cmd {
+	return func() tea.Msg {
+		act, err := fit.ReadActivity(path)
+		if err != nil {
+			return errMsg{fmt.Errorf("failed to crop %s: %v", filepath.Base(path), err)}
+		}
+		if err := fit.CropActivity(act, from, to); err != nil {
+			return errMsg{fmt.Errorf("failed to crop %s: %v", filepath.Base(path), err)}
+		}
+		fit.ApplyPrivacyZones(act, zones)
+		file, err := export.ActivityToFile(dir, export.Name(path)+"-cropped", act)
+		if err != nil {
+			return errMsg{err}
+		}
+		return savedMsg{[]string{file}}
+	}
+}
+
+// Splits an activity at given record (or at its longest pause if `at` is negative)
+// into two new FIT files of given directory. The original file is never changed.
+func splitCmd(dir, path string, at int, zones common.PrivacyZones) tea.Cmd {
+	return func() tea.Msg {
+		act, err := fit.ReadActivity(path)
+		if err != nil {
+			return errMsg{fmt.Errorf("failed to split %s: %v", filepath.Base(path), err)}
+		}
+		if at < 0 {
+			at, err = fit.SplitIndexAtPause(act, fit.DefaultMinSplitPause)
 			if err != nil {
-				act.Data = asyncdata.NewFailure[error, common.ActivityData](err)
-			} else {
-				act.Data = asyncdata.NewSuccess[error, common.ActivityData](*data)
+				return errMsg{fmt.Errorf("failed to split %s: %v", filepath.Base(path), err)}
 			}
-			resultCh <- parseFileResultMsg{act}
-			close(resultCh)
-		}()
-		// return result msg
-		return <-resultCh
+		}
+		first, second, err := fit.SplitActivity(act, at)
+		if err != nil {
+			return errMsg{fmt.Errorf("failed to split %s: %v", filepath.Base(path), err)}
+		}
+		var files []string
+		for i, part := range []*filedef.Activity{first, second} {
+			fit.ApplyPrivacyZones(part, zones)
+			file, err := export.ActivityToFile(dir, fmt.Sprintf("%s-part%d", export.Name(path), i+1), part)
+			if err != nil {
+				return errMsg{err}
+			}
+			files = append(files, file)
+		}
+		return savedMsg{files}
+	}
+}
+
+// Merges activities of given FIT files into a new FIT file of given directory.
+// The original files are never changed.
+func mergeCmd(dir string, paths []string, zones common.PrivacyZones) tea.Cmd {
+	return func() tea.Msg {
+		var acts []*filedef.Activity
+		for _, path := range paths {
+			act, err := fit.ReadActivity(path)
+			if err != nil {
+				return errMsg{fmt.Errorf("failed to merge %s: %v", filepath.Base(path), err)}
+			}
+			acts = append(acts, act)
+		}
+		act, err := fit.MergeActivities(acts)
+		if err != nil {
+			return errMsg{fmt.Errorf("failed to merge: %v", err)}
+		}
+		fit.ApplyPrivacyZones(act, zones)
+		file, err := export.ActivityToFile(dir, export.Name(paths[0])+"-merged", act)
+		if err != nil {
+			return errMsg{err}
+		}
+		return savedMsg{[]string{file}}
+	}
+}
+
+// Exports summaries of activities into a new file of given directory
+func exportSummaryCmd(dir string, acts []*common.Activity) tea.Cmd {
+	return func() tea.Msg {
+		file, err := export.SummaryToFile(dir, acts)
+		if err != nil {
+			return errMsg{err}
+		}
+		return exportedMsg{file}
+	}
+}
+
+// Scans watched sources after `Interval` to detect changes since `prev` snapshot
+func watchCmd(opts WatchOptions, prev watch.Snapshot) tea.Cmd {
+	return tea.Tick(opts.Interval, func(time.Time) tea.Msg {
+		next := watch.Scan(opts.Sources, opts.ImportOptions)
+		return watchMsg{snapshot: next, events: watch.Diff(prev, next)}
+	})
+}
+
+// Applies changes of watched files to activities and list.
+// Created and modified files are (re-)parsed only.
+func (m *Model) applyWatchEvents(events []watch.Event) []tea.Cmd {
+	if len(events) == 0 {
+		return nil
+	}
+	var cmds []tea.Cmd
+	items := m.list.Items()
+	for _, event := range events {
+		index := slices.IndexFunc(m.activities, func(act *common.Activity) bool {
+			return act.Path == event.File.Path
+		})
+		switch event.Kind {
+		case watch.Created, watch.Modified:
+			if index < 0 {
+				m.importFiles = append(m.importFiles, event.File)
+				m.activities = append(m.activities, &common.Activity{
+					Path: event.File.Path,
+				})
+				index = len(m.activities) - 1
+			}
+			act := m.activities[index]
+			m.dropCropMarks(act)
+			// parse again after current parsing (no concurrent parsing of the same file)
+			if _, _, loading := asyncdata.Loading(act.Data); loading {
+				m.outdated[act] = true
+				break
+			}
+			act.Data = asyncdata.NewNotAsked[error, common.ActivityData]()
+		case watch.Deleted:
+			if index < 0 {
+				break
+			}
+			act := m.activities[index]
+			m.dropCropMarks(act)
+			delete(m.outdated, act)
+			m.activities = slices.Delete(m.activities, index, index+1)
+			items = slices.DeleteFunc(items, func(item list.Item) bool {
+				return item == list.Item(act)
+			})
+			m.importFiles = slices.DeleteFunc(m.importFiles, func(file fit.ImportFile) bool {
+				return file.Path == event.File.Path
+			})
+		}
+	}
+
+	// sources are relevant to show for more than one source only
+	showSources := HasMultipleSources(m.importFiles)
+	for _, act := range m.activities {
+		act.Source = ""
+		if showSources {
+			if i := slices.IndexFunc(m.importFiles, func(file fit.ImportFile) bool {
+				return file.Path == act.Path
+			}); i >= 0 {
+				act.Source = m.importFiles[i].Source
+			}
+		}
+	}
+
+	cmds = append(cmds, m.setItems(items))
+
+	// parse changed files concurrently (limited by number of free `jobs`)
+	loading := 0
+	for _, act := range m.activities {
+		if _, _, ok := asyncdata.Loading(act.Data); ok {
+			loading++
+		}
+	}
+	for range max(m.jobs-loading, 0) {
+		cmd := m.parseNextFileCmd()
+		if cmd == nil {
+			break
+		}
+		cmds = append(cmds, cmd)
+	}
+
+	return cmds
+}
+
+// Applies a parsed result to its activity
+func (m *Model) applyParseResult(msg parseFileResultMsg) {
+	msg.act.Data = msg.data
+	// keep current record in range of (re-parsed) records
+	msg.act.CountRecordIndex(0)
+}
+
+// Drops the outdated result of an activity (see `outdated`) and parses it again
+// with the job of the outdated result
+func (m *Model) reparseFileCmd(act *common.Activity) tea.Cmd {
+	delete(m.outdated, act)
+	act.Data = asyncdata.NewNotAsked[error, common.ActivityData]()
+	return m.parseNextFileCmd()
+}
+
+// Drops marks to crop records of an activity (e.g. its file has been changed)
+func (m *Model) dropCropMarks(act *common.Activity) {
+	if m.cropAct == act {
+		m.cropAct = nil
+		m.cropStart, m.cropEnd = -1, -1
+	}
+}
+
+// Parses a file again (e.g. after a failure)
+func retryParseFileCmd(act *common.Activity, c *cache.Cache) tea.Cmd {
+	return func() tea.Msg {
+		msg := parseFileCmd(act, c)()
+		if result, ok := msg.(parseFileResultMsg); ok {
+			return retryParseFileResultMsg(result)
+		}
+		return msg
 	}
 }
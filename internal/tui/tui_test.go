@@ -0,0 +1,125 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sectore/fit-activities-tui/internal/asyncdata"
+	"github.com/sectore/fit-activities-tui/internal/common"
+	"github.com/sectore/fit-activities-tui/internal/fit"
+	"github.com/sectore/fit-activities-tui/internal/watch"
+)
+
+func parsedResult(act *common.Activity, noRecords int) parseFileResultMsg {
+	data := common.ActivityData{Records: make([]common.RecordData, noRecords)}
+	return parseFileResultMsg{act, asyncdata.NewSuccess[error](data)}
+}
+
+func TestParseResultsOfModifiedFile(t *testing.T) {
+	file := fit.ImportFile{Path: "ride.fit"}
+	m := InitialModel([]fit.ImportFile{file}, Options{Jobs: 1})
+	update := func(msg tea.Msg) {
+		next, _ := m.Update(msg)
+		m = next.(Model)
+	}
+	modified := []watch.Event{{Kind: watch.Modified, File: file}}
+
+	update(parseFilesMsg{})
+	act := m.activities[0]
+
+	// modified while parsing: result of the first parsing is outdated
+	m.applyWatchEvents(modified)
+	update(parsedResult(act, 5))
+	if _, _, loading := asyncdata.Loading(act.Data); !loading {
+		t.Fatalf("Expected outdated result to be dropped and file to be parsed again, Got: %+v", act.Data)
+	}
+	update(parsedResult(act, 10))
+	if data, ok := asyncdata.Success(act.Data); !ok || data.NoRecords() != 10 {
+		t.Fatalf("Expected result of 10 records, Got: %+v", act.Data)
+	}
+
+	// last record and crop marks
+	act.CountRecordIndex(9)
+	m.cropAct, m.cropStart, m.cropEnd = act, 2, 9
+
+	// modified file has less records
+	m.applyWatchEvents(modified)
+	if m.cropAct != nil || m.cropStart != -1 || m.cropEnd != -1 {
+		t.Errorf("Expected crop marks to be dropped, Got: %v %d-%d", m.cropAct, m.cropStart, m.cropEnd)
+	}
+	update(parsedResult(act, 3))
+	if act.RecordIndex() != 2 {
+		t.Errorf("Expected last record of re-parsed records: 2, Got: %d", act.RecordIndex())
+	}
+	// renders current record without panic
+	_ = m.RightContentView()
+}
+
+func TestRecomputedTotals(t *testing.T) {
+	file := fit.ImportFile{Path: "ride.fit"}
+	m := InitialModel([]fit.ImportFile{file}, Options{Jobs: 1})
+	update := func(msg tea.Msg) {
+		next, _ := m.Update(msg)
+		m = next.(Model)
+	}
+	update(parseFilesMsg{})
+	act := m.activities[0]
+	// about 1.1km between both positions (device reports 1km)
+	data := common.ActivityData{
+		TotalDistance: common.Ptr(common.NewDistance(100000)),
+		Records: []common.RecordData{
+			{Position: common.Ptr(common.NewPosition(0, 0))},
+			{Position: common.Ptr(common.NewPosition(0, 0.01))},
+		},
+	}
+	update(parseFileResultMsg{act, asyncdata.NewSuccess[error](data)})
+
+	if view := m.RightContentView(); strings.Contains(view, "recomputed") {
+		t.Errorf("Expected no recomputed totals by default, Got: %s", view)
+	}
+	update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("t")})
+	view := m.RightContentView()
+	for _, expected := range []string{"1km", "recomputed    1.1km"} {
+		if !strings.Contains(view, expected) {
+			t.Errorf("Expected %q in details, Got: %s", expected, view)
+		}
+	}
+}
+
+func TestCropMarks(t *testing.T) {
+	file := fit.ImportFile{Path: "ride.fit"}
+	m := InitialModel([]fit.ImportFile{file}, Options{Jobs: 1})
+	update := func(msg tea.Msg) {
+		next, _ := m.Update(msg)
+		m = next.(Model)
+	}
+	key := func(k string) {
+		update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)})
+	}
+	update(parseFilesMsg{})
+	act := m.activities[0]
+	update(parsedResult(act, 10))
+	// live data
+	key("l")
+
+	// end (3. record) marked before start (8. record)
+	act.CountRecordIndex(2)
+	key("]")
+	act.CountRecordIndex(5)
+	key("[")
+	if got := m.cropRangeText(); got != "records 3-8" {
+		t.Errorf("Expected: records 3-8, Got: %s", got)
+	}
+	key("w")
+	if m.infoMsg != "" {
+		t.Errorf("Expected no info, Got: %s", m.infoMsg)
+	}
+
+	// one record only
+	key("]")
+	key("w")
+	if m.infoMsg != "mark at least 2 records to crop" {
+		t.Errorf("Expected info to mark more records, Got: %q", m.infoMsg)
+	}
+}
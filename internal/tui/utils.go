@@ -1,12 +1,16 @@
 package tui
 
 import (
+	"log"
 	"math"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/sectore/fit-activities-tui/internal/asyncdata"
+	"github.com/sectore/fit-activities-tui/internal/cache"
 	"github.com/sectore/fit-activities-tui/internal/common"
+	"github.com/sectore/fit-activities-tui/internal/fit"
+	"github.com/sectore/fit-activities-tui/internal/query"
 )
 
 func ActivitiesParsing(acts common.Activities) bool {
@@ -56,6 +60,35 @@ func ActivitiesTotalDuration(acts common.Activities) common.Duration {
 	return total
 }
 
+// Loads data from cache (if available) or parses given file.
+// Parsed data is stored in cache, but failures are not.
+func ParseFile(path string, c *cache.Cache) (*common.ActivityData, error) {
+	if c != nil {
+		if data, ok := c.Get(path); ok {
+			return data, nil
+		}
+	}
+	data, err := fit.ParseFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if c != nil {
+		if err := c.Put(path, *data); err != nil {
+			log.Printf("failed to cache %s: %v", path, err)
+		}
+	}
+	return data, nil
+}
+
+func HasMultipleSources(files []fit.ImportFile) bool {
+	for _, f := range files {
+		if f.Source != files[0].Source {
+			return true
+		}
+	}
+	return false
+}
+
 func ListItemsToActivities(items []list.Item) common.Activities {
 	var acts common.Activities
 	for _, item := range items {
@@ -74,21 +107,46 @@ func ActivitiesToListItems(acts common.Activities) []list.Item {
 	return items
 }
 
-func SortItems(items []list.Item, sort ActsSort) []list.Item {
-	acts := ListItemsToActivities(items)
-	switch sort {
-	case DistanceAsc:
-		common.SortBy(common.SortByDistance).Sort(acts)
-	case DistanceDesc:
-		common.SortBy(common.SortByDistance).Reverse(acts)
-	case TimeAsc:
-		common.SortBy(common.SortByTime).Sort(acts)
-	case TimeDesc:
-		common.SortBy(common.SortByTime).Reverse(acts)
+// `QueryFilter` is a `list.FilterFunc` to evaluate a filter query (see `query.Parse`)
+// against `FilterFields` of each target (encoded by `Activity.FilterValue`).
+// Matches keep the order of given targets. An invalid query matches nothing.
+func QueryFilter(term string, targets []string) []list.Rank {
+	ranks := []list.Rank{}
+	q, err := query.Parse(term)
+	if err != nil {
+		return ranks
 	}
+	for index, target := range targets {
+		// ignore activities which are not parsed (yet)
+		if target == "" {
+			continue
+		}
+		if q.Match(common.DecodeFilterFields(target)) {
+			ranks = append(ranks, list.Rank{Index: index})
+		}
+	}
+	return ranks
+}
+
+func SortItems(items []list.Item, keys common.SortKeys) []list.Item {
+	acts := ListItemsToActivities(items)
+	keys.Sort(acts)
 	return ActivitiesToListItems(acts)
 }
 
+// Label of sort keys, e.g. "time ↓, distance ↑"
+func SortKeysLabel(keys common.SortKeys) string {
+	labels := make([]string, len(keys))
+	for index, key := range keys {
+		arrow := arrowTop
+		if key.Desc {
+			arrow = arrowDown
+		}
+		labels[index] = key.Field.Label() + " " + arrow
+	}
+	return strings.Join(labels, ", ")
+}
+
 // `HorizontalStackedBar` renders a horizontal bar chart with two stacked values.
 // It proportionally distributes `maxBlocks` between `value1` and `value2` based on their sum,
 // using rounding for accurate representation. If the total is zero or negative, the entire bar is `value1Block`.
@@ -173,3 +231,95 @@ func TimeToDuration(start common.Time, end common.Time) common.Duration {
 	milliseconds := uint32(seconds * 1000)
 	return common.NewDuration(milliseconds)
 }
+
+// Label of speed values. Runs show a pace instead.
+func SpeedLabel(sport common.Sport) string {
+	if sport.IsRunning() {
+		return "pace"
+	}
+	return "speed"
+}
+
+// Date and time range of an activity, e.g. `01.06.25 08:00-09:30`
+func DateText(ad common.ActivityData) string {
+	if ad.StartTime() == nil || ad.FinishTime() == nil {
+		return common.NoDataText
+	}
+	return ad.StartTime().Format() + "-" + ad.FinishTime().FormatHhMm()
+}
+
+// Texts of active duration and pause, `ok` is false if there is no data
+func ActiveTexts(ad common.ActivityData) (active string, pause string, ok bool) {
+	if ad.Duration.Active == nil || ad.Duration.Pause == nil {
+		return "", "", false
+	}
+	pause = "pause " + ad.Duration.Pause.Format()
+	if ad.Duration.Pause.Value <= 0 {
+		pause = "(no pause)"
+	}
+	return ad.Duration.Active.Format(), pause, true
+}
+
+// Texts of average and max. speed (or pace), `ok` is false if there is no data
+func SpeedTexts(ad common.ActivityData) (avg string, max string, ok bool) {
+	if ad.Speed.Avg == nil || ad.Speed.Max == nil {
+		return "", "", false
+	}
+	return "⌀ " + ad.Sport.FormatSpeed(*ad.Speed.Avg), "max " + ad.Sport.FormatSpeed(*ad.Speed.Max), true
+}
+
+// Texts of ascents and descents, `ok` is false if there is no data
+func ElevationTexts(elevation common.ElevationStats) (ascents string, descents string, ok bool) {
+	if elevation.Ascents == nil || elevation.Descents == nil {
+		return "", "", false
+	}
+	return arrowTop + " " + elevation.Ascents.Format(), arrowDown + " " + elevation.Descents.Format(), true
+}
+
+// Texts of average and max. temperature, `ok` is false if there is no data
+func TemperatureTexts(ad common.ActivityData) (avg string, max string, ok bool) {
+	if ad.Temperature.Avg == nil || ad.Temperature.Max == nil {
+		return "", "", false
+	}
+	return "⌀ " + ad.Temperature.Avg.Format(), "max " + ad.Temperature.Max.Format(), true
+}
+
+// Texts of average and worst GPS accuracy, `ok` is false if there is no data
+func GpsAccuracyTexts(ad common.ActivityData) (avg string, worst string, ok bool) {
+	if ad.GpsAccuracy.Avg == nil || ad.GpsAccuracy.Max == nil {
+		return "", "", false
+	}
+	return "⌀ " + ad.GpsAccuracy.Avg.Format(), "worse " + ad.GpsAccuracy.Max.Format(), true
+}
+
+// Texts of average and max. heart rate, `ok` is false if there is no data
+func HeartrateTexts(ad common.ActivityData) (avg string, max string, ok bool) {
+	if ad.Heartrate.Avg == nil || ad.Heartrate.Max == nil {
+		return "", "", false
+	}
+	return "⌀ " + ad.Heartrate.Avg.Format(), "max " + ad.Heartrate.Max.Format(), true
+}
+
+// Texts of average (incl. zeros if different) and max. cadence, `ok` is false if there is no data
+func CadenceTexts(ad common.ActivityData) (avg string, max string, ok bool) {
+	if ad.Cadence.Avg == nil || ad.Cadence.Max == nil {
+		return "", "", false
+	}
+	avg = "⌀ " + ad.Cadence.Avg.Format()
+	if ad.Cadence.AvgWithZeros != nil && ad.Cadence.AvgWithZeros.Value != ad.Cadence.Avg.Value {
+		avg += " (incl. 0: " + ad.Cadence.AvgWithZeros.Format() + ")"
+	}
+	return avg, "max " + ad.Cadence.Max.Format(), true
+}
+
+// Texts of average (incl. normalized power) and max. power, `ok` is false if there is no data
+func PowerTexts(ad common.ActivityData) (avg string, max string, ok bool) {
+	if ad.Power.Avg == nil || ad.Power.Max == nil {
+		return "", "", false
+	}
+	avg = "⌀ " + ad.Power.Avg.Format()
+	if ad.Power.Normalized != nil {
+		avg += " NP " + ad.Power.Normalized.Format()
+	}
+	return avg, "max " + ad.Power.Max.Format(), true
+}
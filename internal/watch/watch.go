@@ -0,0 +1,98 @@
+// Package watch detects created, modified and deleted FIT files of import sources.
+// It compares snapshots of all files, which are taken periodically (polling).
+// Polling supports all kind of sources (directories, globs, recursive imports, network drives)
+// the same way the initial import does.
+package watch
+
+import (
+	"sort"
+	"time"
+
+	"github.com/sectore/fit-activities-tui/internal/fit"
+)
+
+type EventKind int
+
+const (
+	Created EventKind = iota
+	Modified
+	Deleted
+)
+
+func (k EventKind) String() string {
+	switch k {
+	case Created:
+		return "created"
+	case Modified:
+		return "modified"
+	case Deleted:
+		return "deleted"
+	default:
+		return ""
+	}
+}
+
+type Event struct {
+	Kind EventKind
+	File fit.ImportFile
+}
+
+type fileState struct {
+	file    fit.ImportFile
+	size    int64
+	modTime time.Time
+}
+
+// Snapshot of all FIT files of import sources, indexed by path
+type Snapshot map[string]fileState
+
+// Takes a snapshot of all FIT files of given sources
+func Scan(sources []string, opts fit.ImportOptions) Snapshot {
+	return SnapshotOf(fit.FindImportFiles(sources, opts))
+}
+
+// Takes a snapshot of given files (e.g. of the initial import).
+// Files, which don't exist anymore, are ignored.
+func SnapshotOf(files []fit.ImportFile) Snapshot {
+	snapshot := Snapshot{}
+	paths := make([]string, len(files))
+	for i, file := range files {
+		paths[i] = file.Path
+	}
+	infos := fit.StatFiles(paths)
+	for _, file := range files {
+		info, ok := infos[file.Path]
+		if !ok {
+			continue
+		}
+		snapshot[file.Path] = fileState{
+			file:    file,
+			size:    info.Size(),
+			modTime: info.ModTime(),
+		}
+	}
+	return snapshot
+}
+
+// Events of all changes between two snapshots (sorted by path)
+func Diff(prev, next Snapshot) []Event {
+	var events []Event
+	for path, n := range next {
+		p, ok := prev[path]
+		switch {
+		case !ok:
+			events = append(events, Event{Kind: Created, File: n.file})
+		case p.size != n.size || !p.modTime.Equal(n.modTime):
+			events = append(events, Event{Kind: Modified, File: n.file})
+		}
+	}
+	for path, p := range prev {
+		if _, ok := next[path]; !ok {
+			events = append(events, Event{Kind: Deleted, File: p.file})
+		}
+	}
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].File.Path < events[j].File.Path
+	})
+	return events
+}
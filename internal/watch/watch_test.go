@@ -0,0 +1,80 @@
+package watch
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sectore/fit-activities-tui/internal/fit"
+)
+
+func TestScanAndDiff(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("a.fit", "a")
+	write("b.fit", "b")
+	write("notes.txt", "-")
+
+	sources := []string{dir}
+	prev := Scan(sources, fit.ImportOptions{})
+	if len(prev) != 2 {
+		t.Fatalf("Expected 2 files, Got: %d", len(prev))
+	}
+
+	if events := Diff(prev, Scan(sources, fit.ImportOptions{})); len(events) != 0 {
+		t.Errorf("Expected no events, Got: %v", events)
+	}
+	// snapshot of imported files is the same as a scan of its sources
+	files, err := fit.GetImportFiles(sources, fit.ImportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if events := Diff(SnapshotOf(files), prev); len(events) != 0 {
+		t.Errorf("Expected no events, Got: %v", events)
+	}
+
+	// create c, modify b, delete a
+	write("c.fit", "c")
+	write("b.fit", "bb")
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(filepath.Join(dir, "b.fit"), later, later); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(dir, "a.fit")); err != nil {
+		t.Fatal(err)
+	}
+
+	events := Diff(prev, Scan(sources, fit.ImportOptions{}))
+	expected := []struct {
+		kind EventKind
+		name string
+	}{
+		{Deleted, "a.fit"},
+		{Modified, "b.fit"},
+		{Created, "c.fit"},
+	}
+	if len(events) != len(expected) {
+		t.Fatalf("Expected %d events, Got: %v", len(expected), events)
+	}
+	for i, e := range expected {
+		if events[i].Kind != e.kind || filepath.Base(events[i].File.Path) != e.name {
+			t.Errorf("Expected %s %s, Got: %s %s", e.kind, e.name, events[i].Kind, events[i].File.Path)
+		}
+	}
+
+	// all files deleted
+	for _, name := range []string{"b.fit", "c.fit"} {
+		if err := os.Remove(filepath.Join(dir, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if snapshot := Scan(sources, fit.ImportOptions{}); len(snapshot) != 0 {
+		t.Errorf("Expected empty snapshot, Got: %v", snapshot)
+	}
+}